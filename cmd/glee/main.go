@@ -1,13 +1,48 @@
 package main
 
 import (
+	"os"
+	"runtime"
+
+	"github.com/namsral/flag"
 	log "github.com/sirupsen/logrus"
+	commandline "github.com/tonyOreglia/glee/pkg/command-line"
+	"github.com/tonyOreglia/glee/pkg/perftsuite"
+	"github.com/tonyOreglia/glee/pkg/uci"
 	"github.com/tonyOreglia/glee/pkg/websocket"
 )
 
 func main() {
 	log.SetFormatter(&log.JSONFormatter{})
-	server := websocket.NewWebsocketServer()
-	log.Info("starting websocket server")
-	server.Start()
+	addr := flag.String("addr", "localhost:8081", "http websocket service address")
+	mode := flag.String("mode", "websocket", "websocket: serve UCI over websockets, uci: speak UCI over stdin/stdout, cli: interactive command line, perftsuite <file.epd>: check perft counts")
+	depth := flag.Int("depth", 0, "perftsuite: highest depth to check, 0 checks every depth in the file")
+	workers := flag.Int("workers", runtime.NumCPU(), "perftsuite: number of positions checked in parallel")
+	pgnPath := flag.String("pgn", "", "websocket: file that every game played is appended to in PGN")
+	flag.Parse()
+	if flag.NArg() > 0 {
+		*mode = flag.Arg(0)
+	}
+	switch *mode {
+	case "uci":
+		if err := uci.Run(os.Stdin, os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+	case "cli":
+		commandline.CLI()
+	case "perftsuite":
+		entries, err := perftsuite.ReadFile(flag.Arg(1))
+		if err != nil {
+			log.Fatal(err)
+		}
+		if perftsuite.Report(os.Stdout, perftsuite.Run(entries, *depth, *workers)) > 0 {
+			os.Exit(1)
+		}
+	case "websocket":
+		server := websocket.NewWebsocketServer(*addr, *pgnPath)
+		log.Info("starting websocket server")
+		server.Start()
+	default:
+		log.Fatalf("unknown mode: %s", *mode)
+	}
 }
@@ -1,13 +1,17 @@
 package commandline
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"time"
 
+	"github.com/tonyOreglia/glee/pkg/engine"
 	"github.com/tonyOreglia/glee/pkg/evaluate"
 	"github.com/tonyOreglia/glee/pkg/generate"
-	"github.com/tonyOreglia/glee/pkg/moves"
+	"github.com/tonyOreglia/glee/pkg/pgn"
 	"github.com/tonyOreglia/glee/pkg/position"
+	"github.com/tonyOreglia/glee/pkg/uci"
 )
 
 const version = "0.0.1"
@@ -15,8 +19,10 @@ const version = "0.0.1"
 func CLI() {
 	command := make([]byte, 0, 100)
 	pos := position.StartingPosition()
-	mvs := generate.GenerateMoves(pos)
-	var move *moves.Move
+	mvs := generate.GenerateLegalMoves(pos)
+	tt := engine.NewTranspositionTable(engine.DefaultHashSizeMb)
+	// game is the last game played or loaded, it can be saved as PGN
+	var game *pgn.Game
 	for true {
 		fmt.Print("glee: ")
 		_, err := fmt.Scan(&command)
@@ -33,32 +39,61 @@ func CLI() {
 			pos.PrintFen()
 		case "new":
 			pos = position.StartingPosition()
+			tt.Clear()
 		case "disp":
 			pos.Print()
 		case "eval":
 			fmt.Printf("score: %d\n", evaluate.EvaluatePosition(pos))
 		case "uci":
-			// UCI()
+			if err := uci.Run(os.Stdin, os.Stdout); err != nil {
+				fmt.Println(err)
+			}
+			return
 		case "undo":
 			undo(pos)
-			mvs = generate.GenerateMoves(pos)
+			mvs = generate.GenerateLegalMoves(pos)
 		case "search":
-			pos, move = search(pos, mvs)
-			move.Print()
-			mvs = generate.GenerateMoves(pos)
+			result := engine.Search(context.Background(), pos, engine.Limits{TT: tt})
+			result.BestMove.Print()
+			fmt.Printf("score: %d nodes: %d qnodes: %d\n", result.Score, result.Nodes, result.QNodes)
+		case "sort":
+			printSortedMoves(pos, tt)
+		case "perft", "perft2":
+			if depth, ok := readDepth(); ok {
+				perft(pos, depth, c == "perft2")
+			}
+		case "divide", "divide2":
+			if depth, ok := readDepth(); ok {
+				divide(pos, depth, c == "divide2")
+			}
 		case "setboard":
-			setboard(pos)
+			pos = setboard(pos)
+			mvs = generate.GenerateLegalMoves(pos)
+			tt.Clear()
 		case "playw":
 			pos = position.StartingPosition()
+			tt.Clear()
 			pos.Print()
-			pos = play(pos, 0)
+			game = play(pos, 0, tt)
 		case "playb":
 			pos = position.StartingPosition()
-			pos = play(pos, 1)
+			tt.Clear()
+			game = play(pos, 1, tt)
+		case "pgn":
+			if game != nil {
+				fmt.Print(game.String())
+			}
+		case "savepgn":
+			savePgn(game)
+		case "loadpgn":
+			if loaded, p := loadPgn(); loaded != nil {
+				game, pos = loaded, p
+				tt.Clear()
+			}
 		default:
 			handleMove(c, pos, mvs)
 			pos.Print()
-			mvs = generate.GenerateMoves(pos)
+			mvs = generate.GenerateLegalMoves(pos)
 		}
 	}
 }
@@ -69,6 +104,7 @@ func printHelp() {
 	fmt.Println("uci.............switch to uci-mode")
 	fmt.Println("e2e4............moves piece")
 	fmt.Println("e7e8Q...........promotion move resulting in Queen [Q,R,B,N]")
+	fmt.Println("Nf3.............moves piece in SAN, e.g. exd5 O-O e8=Q")
 	// fmt.Println("st #............sets search time per move (1-300s)")
 	// fmt.Println("sd #............sets search depth (1-9)")
 	fmt.Println("undo............takes back last move")
@@ -77,23 +113,37 @@ func printHelp() {
 	fmt.Println("search..........engine plays the current position")
 	fmt.Println("playw...........play white vs engine as black")
 	fmt.Println("playb...........play black vs engine as white")
-	// fmt.Println("divide #........outputs the numbers of child moves")
-	// fmt.Println("divide2 #.......outputs the total numbers of child moves")
-	// fmt.Println("perft #.........counts nodes at given depth")
-	// fmt.Println("perft2 #........counts all nodes to given depth")
+	fmt.Println("pgn.............outputs the last game played as PGN")
+	fmt.Println("savepgn <file>..saves the last game played as PGN")
+	fmt.Println("loadpgn <file>..plays through the first game of a PGN file")
+	fmt.Println("divide #........outputs the numbers of child moves")
+	fmt.Println("divide2 #.......outputs the total numbers of child moves")
+	fmt.Println("perft #.........counts nodes at given depth")
+	fmt.Println("perft2 #........counts all nodes to given depth")
 	fmt.Println("setboard <FEN>..reads a fen-string")
 	fmt.Println("fen.............outputs FEN of board position")
 	// fmt.Println("info............outputs data-structure")
 	fmt.Println("eval............evaluates position")
 	// fmt.Println("analyze.........infinite analysis")
 	// fmt.Println("stack...........shows move-stack")
-	// fmt.Println("sort............gives sorted move-list for Alpha-Beta")
+	fmt.Println("sort............gives sorted move-list for Alpha-Beta")
 	// fmt.Println("show............gives valid moves for current pos.")
 }
 
-func play(p *position.Position, humanSide int) *position.Position {
+// play lets the human play humanSide against the engine until the game is over or the human quits.
+// It returns the game played.
+func play(p *position.Position, humanSide int, tt *engine.TranspositionTable) *pgn.Game {
+	game := pgn.NewGame()
+	game.SetTag("Date", time.Now().Format("2006.01.02"))
+	game.SetTag("White", "human")
+	game.SetTag("Black", "glee "+version)
+	if humanSide == position.Black {
+		game.SetTag("White", "glee "+version)
+		game.SetTag("Black", "human")
+	}
+	game.SetStartingPosition(p)
 	move := make([]byte, 0, 100)
-	for true {
+	for pgn.Result(p) == pgn.Unknown {
 		if p.GetActiveSide() == humanSide {
 			for true {
 				fmt.Print("human move: ")
@@ -102,20 +152,23 @@ func play(p *position.Position, humanSide int) *position.Position {
 					fmt.Print(err)
 				}
 				if string(move) == "quit" {
-					return p
+					return game
 				}
-				if handleMove(string(move), p, generate.GenerateMoves(p)) {
+				if mv, found := findMove(string(move), p, generate.GenerateLegalMoves(p)); found {
+					game.AddMove(p, mv)
 					break
 				}
+				badInput(string(move))
 			}
 		} else {
-			var mv *moves.Move
-			p, mv = search(p, generate.GenerateMoves(p))
-			p.Move(*mv)
-			p.Print()
+			result := engine.Search(context.Background(), p, engine.Limits{TT: tt})
 			fmt.Print("glee move: ")
-			mv.Print()
+			result.BestMove.Print()
+			game.AddMove(p, result.BestMove)
+			p.Print()
 		}
 	}
-	return p
+	game.SetResult(pgn.Result(p))
+	fmt.Printf("game over: %s\n", game.Result)
+	return game
 }
@@ -0,0 +1,63 @@
+package commandline
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tonyOreglia/glee/pkg/engine"
+	"github.com/tonyOreglia/glee/pkg/position"
+)
+
+// readDepth reads the depth following a perft or divide command
+func readDepth() (int, bool) {
+	var depth int
+	if _, err := fmt.Scan(&depth); err != nil || depth < 1 {
+		badInput("depth must be a positive number")
+		return 0, false
+	}
+	return depth, true
+}
+
+// perft prints the leaf nodes at depth, or with cumulative the nodes at every depth up to it
+func perft(p *position.Position, depth int, cumulative bool) {
+	start := time.Now()
+	total := 0
+	for d := 1; d <= depth; d++ {
+		if !cumulative && d < depth {
+			continue
+		}
+		nodes := engine.Perft(p, d)
+		total += nodes
+		fmt.Printf("perft %d: %d\n", d, nodes)
+	}
+	printNodeRate(total, time.Since(start))
+}
+
+// divide prints the leaf nodes below every move at depth, or with cumulative
+// the nodes below every move at every depth up to it
+func divide(p *position.Position, depth int, cumulative bool) {
+	start := time.Now()
+	counts := engine.Divide(p, depth)
+	if cumulative {
+		for d := 1; d < depth; d++ {
+			for i, count := range engine.Divide(p, d) {
+				counts[i].Nodes += count.Nodes
+			}
+		}
+	}
+	total := 0
+	for _, count := range counts {
+		total += count.Nodes
+		fmt.Printf("%s: %d\n", count.Move.String(), count.Nodes)
+	}
+	fmt.Printf("\nmoves: %d\n", len(counts))
+	printNodeRate(total, time.Since(start))
+}
+
+func printNodeRate(nodes int, elapsed time.Duration) {
+	nps := 0
+	if elapsed > 0 {
+		nps = int(float64(nodes) / elapsed.Seconds())
+	}
+	fmt.Printf("nodes: %d time: %dms nps: %d\n", nodes, elapsed.Milliseconds(), nps)
+}
@@ -1,17 +1,38 @@
 package commandline
 
 import (
-	"bufio"
 	"fmt"
-	"log"
 	"os"
+	"strings"
 
 	"github.com/tonyOreglia/glee/pkg/engine"
 	"github.com/tonyOreglia/glee/pkg/moves"
+	"github.com/tonyOreglia/glee/pkg/pgn"
 	"github.com/tonyOreglia/glee/pkg/position"
+	"github.com/tonyOreglia/glee/pkg/san"
 )
 
+// handleMove plays mv given as coordinates (e2e4, e7e8Q) or in SAN (Nf3, exd5, O-O)
 func handleMove(mv string, p *position.Position, mvs *moves.Moves) bool {
+	move, found := findMove(mv, p, mvs)
+	if !found {
+		badInput(mv)
+		return false
+	}
+	p.Move(move)
+	return true
+}
+
+// findMove looks up mv given as coordinates or in SAN among the legal moves mvs of p
+func findMove(mv string, p *position.Position, mvs *moves.Moves) (moves.Move, bool) {
+	if move, found := findCoordinateMove(mv, mvs); found {
+		return move, true
+	}
+	move, err := san.Parse(p, mv)
+	return move, err == nil
+}
+
+func findCoordinateMove(mv string, mvs *moves.Moves) (moves.Move, bool) {
 	lookupPromo := map[string]int{
 		// Queen = 2 Bishops = 3 Knights = 4 Rooks = 5
 		"Q": 2,
@@ -21,65 +42,36 @@ func handleMove(mv string, p *position.Position, mvs *moves.Moves) bool {
 	}
 	promotionPiece := 0
 	if len(mv) != 4 && len(mv) != 5 {
-		badInput(mv)
-		return false
+		return 0, false
 	}
 	if len(mv) == 5 {
-		promotionPiece = lookupPromo[string(mv[4])]
+		promotionPiece = lookupPromo[strings.ToUpper(string(mv[4]))]
 	}
 	origin, err := moves.ConvertAlgebriacToIndex(mv[0:2])
 	if err != nil {
-		badInput(mv)
-		return false
+		return 0, false
 	}
 	dest, err := moves.ConvertAlgebriacToIndex(mv[2:4])
 	if err != nil {
-		badInput(mv)
-		return false
-	}
-	move, found := mvs.FindMove(origin, dest, promotionPiece)
-	if !found {
-		badInput(mv)
-		return false
-	}
-	if !engine.MakeValidMove(move, &p) {
-		badInput(mv)
-		return false
+		return 0, false
 	}
-	return true
+	return mvs.FindMove(origin, dest, promotionPiece)
 }
 
-func setboard(p *position.Position) {
-	in := bufio.NewReader(os.Stdin)
-	fen, err := in.ReadString('\n')
+// setboard reads a FEN and returns its position, or p when the FEN is invalid
+func setboard(p *position.Position) *position.Position {
+	fen, err := readLine()
 	if err != nil {
-		log.Fatal(err)
+		badInput(err.Error())
+		return p
 	}
-	fen = fen[0 : len(fen)-1]
-	p, err = position.NewPositionFen(string(fen))
+	newPos, err := position.NewPositionFen(strings.TrimSpace(fen))
 	if err != nil {
-		badInput(string(fen))
+		badInput(err.Error())
+		return p
 	}
-	p.Print()
-}
-
-func search(p *position.Position, mvs *moves.Moves) (*position.Position, *moves.Move) {
-	perft := 0
-	singlePlyPerft := 0
-	params := engine.SearchParams{
-		Depth:          5,
-		Ply:            5,
-		Pos:            &p,
-		Perft:          &perft,
-		SinglePlyPerft: &singlePlyPerft,
-		EngineMove:     &moves.Move{},
-	}
-	if p.IsWhitesTurn() {
-		engine.AlphaBetaMax(-10000, 10000, 5, params)
-	} else {
-		engine.AlphaBetaMin(-10000, 10000, 5, params)
-	}
-	return p, params.EngineMove
+	newPos.Print()
+	return newPos
 }
 
 func badInput(c string) {
@@ -87,11 +79,78 @@ func badInput(c string) {
 }
 
 func undo(p *position.Position) {
-	newPos := p.UnMakeMove()
-	if newPos != nil {
-		p = newPos
+	if p.UnmakeMove() {
 		p.Print()
 		return
 	}
 	badInput("no previous move to undo")
 }
+
+// printSortedMoves prints the legal moves in the order alpha beta searches them
+func printSortedMoves(p *position.Position, tt *engine.TranspositionTable) {
+	var sans []string
+	for _, mv := range engine.OrderedMoves(p, tt) {
+		sans = append(sans, san.Format(p, mv))
+	}
+	fmt.Println(strings.Join(sans, " "))
+}
+
+// savePgn writes game to the file named on the command line
+func savePgn(game *pgn.Game) {
+	var path string
+	if _, err := fmt.Scan(&path); err != nil {
+		badInput("savepgn <file>")
+		return
+	}
+	if game == nil {
+		badInput("no game played yet")
+		return
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		badInput(err.Error())
+		return
+	}
+	defer f.Close()
+	if err := game.Write(f); err != nil {
+		badInput(err.Error())
+	}
+}
+
+// loadPgn reads the first game of the file named on the command line and returns
+// the game with the position at its end, or nil when the file can not be read
+func loadPgn() (*pgn.Game, *position.Position) {
+	var path string
+	if _, err := fmt.Scan(&path); err != nil {
+		badInput("loadpgn <file>")
+		return nil, nil
+	}
+	games, err := pgn.ReadFile(path)
+	if err != nil || len(games) == 0 {
+		badInput(fmt.Sprintf("%s: %v", path, err))
+		return nil, nil
+	}
+	p, err := games[0].Position()
+	if err != nil {
+		badInput(err.Error())
+		return nil, nil
+	}
+	p.Print()
+	return games[0], p
+}
+
+// readLine reads the rest of the line from stdin. It reads a byte at a time so that
+// nothing past the line is consumed, commands are read with fmt.Scan.
+func readLine() (string, error) {
+	var line []byte
+	b := make([]byte, 1)
+	for {
+		if _, err := os.Stdin.Read(b); err != nil {
+			return string(line), err
+		}
+		if b[0] == '\n' {
+			return string(line), nil
+		}
+		line = append(line, b[0])
+	}
+}
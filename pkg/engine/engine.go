@@ -1,16 +1,12 @@
 package engine
 
 import (
-	"github.com/tonyOreglia/glee/pkg/bitboard"
 	"github.com/tonyOreglia/glee/pkg/evaluate"
 	"github.com/tonyOreglia/glee/pkg/generate"
-	"github.com/tonyOreglia/glee/pkg/hashtables"
 	"github.com/tonyOreglia/glee/pkg/moves"
 	"github.com/tonyOreglia/glee/pkg/position"
 )
 
-var ht = hashtables.Lookup
-
 type SearchParams struct {
 	Depth           int
 	Ply             int
@@ -20,6 +16,12 @@ type SearchParams struct {
 	SinglePlyPerft  *int
 	EvaluationScore int
 	Root            bool
+	TT              *TranspositionTable
+	state           *searchState
+	// afterNullMove is set in the search of a null move, two null moves in a row prove nothing
+	afterNullMove bool
+	// extensions counts the plies the path to the node has been extended by
+	extensions int
 }
 
 func MinMax(p SearchParams) int {
@@ -32,19 +34,17 @@ func MinMax(p SearchParams) int {
 	if (*p.Pos).IsWhitesTurn() {
 		p.EvaluationScore = -30000
 	}
-	mvs := generate.GenerateMoves(*p.Pos)
+	mvs := generate.GenerateLegalMoves(*p.Pos)
 	mvList := mvs.GetMovesList()
 	for _, move := range mvList {
-		if !MakeValidMove(move, p.Pos) {
-			continue
-		}
+		(*p.Pos).Move(move)
 		evaluateMove(move, p)
 	}
 	return p.EvaluationScore
 }
 
-// evaluateMove checks if pseudo legal move is valid, and if so
-// increments Ply and calls MinMax to continue the search
+// evaluateMove decrements Ply and calls MinMax to continue the search
+// after move has been made, then takes the move back
 func evaluateMove(move moves.Move, p SearchParams) error {
 	p.Ply = p.Ply - 1
 	temp := MinMax(p)
@@ -60,97 +60,114 @@ func evaluateMove(move moves.Move, p SearchParams) error {
 		*p.Perft += *p.SinglePlyPerft
 		*p.SinglePlyPerft = 0
 	}
-	*p.Pos = (*p.Pos).UnMakeMove()
+	(*p.Pos).UnmakeMove()
 	return nil
 }
 
+// MakeValidMove makes move when it is legal in *pos and reports whether it was made
 func MakeValidMove(move moves.Move, pos **position.Position) bool {
-	if (*pos).IsCastlingMove(move) {
-		(*pos).Move(move)
-		if !castlingMoveIsValid(move, pos) {
-			*pos = (*pos).UnMakeMove()
-			return false
-		}
-	} else {
-		(*pos).Move(move)
-	}
-	legalMoves := generate.GenerateMoves(*pos)
-	if (*pos).IsAttacked((*pos).InactiveSideKingBb(), legalMoves.AttackedSqsBb()) {
-		*pos = (*pos).UnMakeMove()
+	legalMoves := generate.GenerateLegalMoves(*pos)
+	if _, found := legalMoves.FindMove(move.Origin(), move.Destination(), move.PromotionPiece()); !found {
 		return false
 	}
+	(*pos).Move(move)
 	return true
 }
 
-func castlingMoveIsValid(move moves.Move, pos **position.Position) bool {
-	kingPosition := bitboard.NewBitboardFromIndex(move.Origin())
-	legalMoves := generate.GenerateMoves(*pos)
-	castlingSlidingSqBb := bitboard.NewBitboard(uint64(0))
-	castlingSlidingSqBb.SetBit(int(ht.LookupCastlingSlidingSqByDest[uint64(move.Destination())]))
-	castlingSlidingSqBb.Combine(kingPosition)
-	pawnAttacks := generate.GeneratePotentialPawnAttacks(*pos, ht)
-	if (*pos).IsAttacked(*castlingSlidingSqBb, bitboard.ReturnCombined(legalMoves.AttackedSqsBb(), pawnAttacks.AttackedSqsBb())) {
-		return false
-	}
-	return true
+// isDraw reports whether the game is drawn by the fifty move rule, lack of material or repetition.
+// A position that occurs a second time is already scored as a draw, either side could repeat it again.
+func isDraw(pos *position.Position) bool {
+	return pos.IsFiftyMoveDraw() || pos.IsInsufficientMaterial() || pos.RepetitionCount() > 0
 }
 
-func AlphaBetaMax(alpha int, beta int, ply int, p SearchParams) int {
-	noMoves := true
+// AlphaBeta is a negamax principal variation search. ply is the remaining depth and the
+// score is from the point of view of the side to move. The first move is searched with the
+// full window, the others with a null window that only proves they are no better,
+// and a move that turns out better is searched again with the full window.
+func AlphaBeta(alpha int, beta int, ply int, p SearchParams) int {
 	if ply == 0 {
-		return evaluate.EvaluatePosition(*p.Pos)
+		return Quiesce(alpha, beta, p.Depth, p)
+	}
+	height := p.Depth - ply
+	p.state.visitNode(height)
+	if p.state.shouldStop() {
+		return 0
 	}
 	p.Root = ply == p.Depth
-	mvs := generate.GenerateMoves(*p.Pos).GetMovesList()
-	for _, move := range mvs {
-		if MakeValidMove(move, p.Pos) {
-			noMoves = false
-			score := AlphaBetaMin(alpha, beta, ply-1, p)
-			*p.Pos = (*p.Pos).UnMakeMove()
-			if score >= beta {
-				return beta
-			}
-			if score > alpha {
-				alpha = score
-				if p.Root {
-					*p.EngineMove = move
-				}
-			}
+	if !p.Root && isDraw(*p.Pos) {
+		return 0
+	}
+	hash := (*p.Pos).Hash()
+	// a cutoff ends the node without a principal variation, so it is only taken in null
+	// window nodes whose moves never make it into the principal variation
+	if !p.Root && beta-alpha == 1 {
+		if score, ok := p.TT.cutoff(hash, ply, height, alpha, beta); ok {
+			return score
 		}
 	}
-	if noMoves {
-		// check for mate situation
-		return -2500
+	inCheck := (*p.Pos).InCheck()
+	if nullMovePrunes(alpha, beta, ply, inCheck, p) {
+		return beta
 	}
-	return alpha
-}
-
-func AlphaBetaMin(alpha int, beta int, ply int, p SearchParams) int {
-	noMoves := true
-	if ply == 0 {
-		return evaluate.EvaluatePosition(*p.Pos)
+	if p.state.shouldStop() {
+		return 0
 	}
-	p.Root = ply == p.Depth
-	mvs := generate.GenerateMoves(*p.Pos).GetMovesList()
+	p.afterNullMove = false
+	bound := UpperBound
+	var bestMove moves.Move
+	moveNumber := 0
+	mvs := generate.GenerateLegalMoves(*p.Pos).GetMovesList()
+	p.state.orderMoves(*p.Pos, mvs, hashMove(p.TT, hash), height)
 	for _, move := range mvs {
-		if MakeValidMove(move, p.Pos) {
-			noMoves = false
-			score := AlphaBetaMax(alpha, beta, ply-1, p)
-			*p.Pos = (*p.Pos).UnMakeMove()
-			if score <= alpha {
-				return alpha
+		if p.Root && !p.state.isSearchMove(move) {
+			continue
+		}
+		(*p.Pos).Move(move)
+		moveNumber++
+		if p.Root {
+			p.state.reportCurrMove(p.Depth, move, moveNumber)
+		}
+		e := extension(len(mvs), p)
+		child := p.extended(e)
+		var score int
+		if moveNumber == 1 {
+			score = -AlphaBeta(-beta, -alpha, ply-1+e, child)
+		} else {
+			r := lateMoveReduction(ply, height, moveNumber, move, inCheck, p)
+			score = -AlphaBeta(-alpha-1, -alpha, ply-1+e-r, child.reduced(r))
+			if score > alpha && r > 0 {
+				score = -AlphaBeta(-alpha-1, -alpha, ply-1+e, child)
 			}
-			if score < beta {
-				beta = score
-				if p.Root {
-					*p.EngineMove = move
-				}
+			if score > alpha && score < beta {
+				score = -AlphaBeta(-beta, -alpha, ply-1+e, child)
+			}
+		}
+		(*p.Pos).UnmakeMove()
+		if p.state.shouldStop() {
+			return 0
+		}
+		if score >= beta {
+			p.state.storeCutoff(*p.Pos, move, height, ply)
+			p.TT.Store(hash, ply, scoreToTT(beta, height), LowerBound, move)
+			return beta
+		}
+		if score > alpha {
+			alpha = score
+			bound = ExactBound
+			bestMove = move
+			p.state.updatePV(height, move)
+			if p.Root {
+				*p.EngineMove = move
 			}
 		}
 	}
-	if noMoves {
-		// check for mate situation
-		return 2500
+	if moveNumber == 0 {
+		if inCheck {
+			return -MateScore + height
+		}
+		// stalemate
+		return 0
 	}
-	return beta
+	p.TT.Store(hash, ply, scoreToTT(alpha, height), bound, bestMove)
+	return alpha
 }
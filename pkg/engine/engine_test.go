@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/tonyOreglia/glee/pkg/generate"
 	"github.com/tonyOreglia/glee/pkg/moves"
 	"github.com/tonyOreglia/glee/pkg/position"
 )
@@ -52,7 +53,7 @@ func TestMinMax(t *testing.T) {
 		pos, _ := position.NewPositionFen(tt.fen)
 		var evalScore int
 		var root bool
-		engineMove := &moves.Move{}
+		engineMove := new(moves.Move)
 		MinMax(SearchParams{
 			Depth:          	tt.depth,
 			Ply:            	tt.depth,
@@ -67,6 +68,33 @@ func TestMinMax(t *testing.T) {
 	}
 }
 
+func TestZobristHashIncrementalUpdate(t *testing.T) {
+	for _, tt := range flagtests {
+		pos, _ := position.NewPositionFen(tt.fen)
+		depth := tt.depth
+		if depth > 3 {
+			depth = 3
+		}
+		verifyHashAfterEveryMove(t, &pos, depth, tt.fen)
+	}
+}
+
+func verifyHashAfterEveryMove(t *testing.T, pos **position.Position, depth int, fen string) {
+	if depth == 0 {
+		return
+	}
+	for _, move := range generate.GenerateMoves(*pos).GetMovesList() {
+		if !MakeValidMove(move, pos) {
+			continue
+		}
+		if (*pos).Hash() != (*pos).CalculateHash() {
+			t.Fatalf("incremental hash mismatch after %s from %s: %s", move.String(), fen, (*pos).GetFenString())
+		}
+		verifyHashAfterEveryMove(t, pos, depth-1, fen)
+		(*pos).UnmakeMove()
+	}
+}
+
 func TestMakeValidMove(t *testing.T) {
 	tests := map[string]struct {
 		move  *moves.Move
@@ -75,62 +103,62 @@ func TestMakeValidMove(t *testing.T) {
 	}{
 		"black cannot castle through check kingside": {
 			move:  moves.NewMove([]int{4, 6}),
-			pos:   "4k2r/8/8/8/8/8/5R2/7K b KQkq - 0 1",
+			pos:   "4k2r/8/8/8/8/8/5R2/6K1 b k - 0 1",
 			legal: false,
 		},
 		"white cannot castle through check kingside": {
 			move:  moves.NewMove([]int{60, 62}),
-			pos:   "7k/5r2/8/8/8/8/8/4K2R w K - 0 1",
+			pos:   "6k1/5r2/8/8/8/8/8/4K2R w K - 0 1",
 			legal: false,
 		},
 		"black cannot castle through check by pawn kingside": {
 			move:  moves.NewMove([]int{4, 6}),
-			pos:   "4k2r/4P3/8/8/8/8/8/7K b KQkq - 0 1",
+			pos:   "4k2r/4P3/8/8/8/8/8/6K1 b k - 0 1",
 			legal: false,
 		},
 		"white cannot castle through check by pawn kingside": {
 			move:  moves.NewMove([]int{60, 62}),
-			pos:   "7k/8/8/8/8/8/4p3/4K2R w K - 0 1",
+			pos:   "6k1/8/8/8/8/8/4p3/4K2R w K - 0 1",
 			legal: false,
 		},
 		"black cannot castle through check queenside": {
 			move:  moves.NewMove([]int{4, 2}),
-			pos:   "r3k2r/8/8/8/8/8/3R4/7K b q - 0 1",
+			pos:   "r3k2r/8/8/8/8/8/3R4/6K1 b q - 0 1",
 			legal: false,
 		},
 		"white cannot castle through check queenside": {
 			move:  moves.NewMove([]int{60, 58}),
-			pos:   "7k/3r4/8/8/8/8/8/R3K2R w Q - 0 1",
+			pos:   "6k1/3r4/8/8/8/8/8/R3K2R w Q - 0 1",
 			legal: false,
 		},
 		"black cannot castle through check by pawn queenside": {
 			move:  moves.NewMove([]int{4, 2}),
-			pos:   "r3k2r/4P3/8/8/8/8/8/7K b q - 0 1",
+			pos:   "r3k2r/4P3/8/8/8/8/8/6K1 b q - 0 1",
 			legal: false,
 		},
 		"white cannot castle through check by pawn queenside": {
 			move:  moves.NewMove([]int{60, 58}),
-			pos:   "7k/8/8/8/8/8/4p3/R3K2R w Q - 0 1",
+			pos:   "6k1/8/8/8/8/8/4p3/R3K2R w Q - 0 1",
 			legal: false,
 		},
 		"black can castle kingside": {
 			move:  moves.NewMove([]int{4, 6}),
-			pos:   "r3k2r/8/8/8/8/8/8/7K b q - 0 1",
+			pos:   "r3k2r/8/8/8/8/8/8/6K1 b k - 0 1",
 			legal: true,
 		},
 		"white can castle kingside": {
 			move:  moves.NewMove([]int{60, 62}),
-			pos:   "7k/8/8/8/8/8/8/R3K2R w Q - 0 1",
+			pos:   "6k1/8/8/8/8/8/8/R3K2R w K - 0 1",
 			legal: true,
 		},
 		"black can castle queenside": {
 			move:  moves.NewMove([]int{4, 2}),
-			pos:   "r3k2r/8/8/8/8/8/8/7K b q - 0 1",
+			pos:   "r3k2r/8/8/8/8/8/8/6K1 b q - 0 1",
 			legal: true,
 		},
 		"white can castle queenside": {
 			move:  moves.NewMove([]int{60, 58}),
-			pos:   "7k/8/8/8/8/8/8/R3K2R w Q - 0 1",
+			pos:   "6k1/8/8/8/8/8/8/R3K2R w Q - 0 1",
 			legal: true,
 		},
 		"black cannot castle into check queenside": {
@@ -140,22 +168,22 @@ func TestMakeValidMove(t *testing.T) {
 		},
 		"white cannot castle into check queenside": {
 			move:  moves.NewMove([]int{60, 58}),
-			pos:   "7k/8/7b/8/8/8/8/R3K2R w KQq - 0 1",
+			pos:   "6k1/8/7b/8/8/8/8/R3K2R w KQ - 0 1",
 			legal: false,
 		},
 		"black cannot castle into check kingside": {
 			move:  moves.NewMove([]int{4, 6}),
-			pos:   "r3k2r/8/8/8/8/8/B7/7K b q - 0 1",
+			pos:   "r3k2r/8/8/8/8/8/B7/6K1 b q - 0 1",
 			legal: false,
 		},
 		"white cannot castle into check kingside": {
 			move:  moves.NewMove([]int{60, 62}),
-			pos:   "7k/8/8/8/8/5n2/8/R3K2R w KQq - 0 1",
+			pos:   "6k1/8/8/8/8/5n2/8/R3K2R w KQ - 0 1",
 			legal: false,
 		},
 		"black cannot castle out of check": {
 			move:  moves.NewMove([]int{4, 6}),
-			pos:   "r3k2r/8/8/4R3/8/8/8/7K b q - 0 1",
+			pos:   "r3k2r/8/8/4R3/8/8/8/6K1 b q - 0 1",
 			legal: false,
 		},
 		"black king cannot castle kingside through check from pawn": {
@@ -189,3 +217,26 @@ func TestMakeValidMove(t *testing.T) {
 		assert.Equal(t, test.legal, MakeValidMove(*test.move, &pos), tName)
 	}
 }
+
+// BenchmarkPerft runs the perft test positions, capped at depth 3 to keep each iteration short.
+// BenchmarkSlidingMoves in generate compares the sliding move lookup with the ray walk it replaced.
+func BenchmarkPerft(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		for _, tt := range flagtests {
+			perft, singlePlyPerft := setup()
+			pos, _ := position.NewPositionFen(tt.fen)
+			depth := tt.depth
+			if depth > 3 {
+				depth = 3
+			}
+			MinMax(SearchParams{
+				Depth:          depth,
+				Ply:            depth,
+				Pos:            &pos,
+				Perft:          &perft,
+				SinglePlyPerft: &singlePlyPerft,
+				EngineMove:     new(moves.Move),
+			})
+		}
+	}
+}
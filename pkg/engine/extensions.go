@@ -0,0 +1,23 @@
+package engine
+
+// extended returns p for a child searched e plies deeper. Like reduced, Depth is raised
+// with the remaining depth so that the height of the child stays right.
+func (p SearchParams) extended(e int) SearchParams {
+	p.Depth += e
+	p.extensions += e
+	return p
+}
+
+// extension returns how many plies deeper the move just made is searched. A move that
+// gives check and the only legal move of a position are searched a ply deeper, so that
+// forcing lines are followed past the horizon. A path gets at most as many extensions
+// as the iteration is deep, it is never searched more than twice as deep.
+func extension(legalMoves int, p SearchParams) int {
+	if p.state == nil || p.Depth >= maxPly || p.extensions >= p.Depth-p.extensions {
+		return 0
+	}
+	if legalMoves == 1 || (*p.Pos).InCheck() {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,61 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tonyOreglia/glee/pkg/generate"
+	"github.com/tonyOreglia/glee/pkg/position"
+)
+
+// mateSuite holds positions with a forced mate in the given number of moves
+var mateSuite = []struct {
+	fen    string
+	mateIn int
+}{
+	{"r1bqkbnr/pppp1ppp/2n5/4p3/2B1P3/5Q2/PPPP1PPP/RNB1K1NR w KQkq - 0 1", 1},
+	{"6k1/5ppp/8/8/8/8/5PPP/3RR1K1 w - - 0 1", 1},
+	{"6rk/6pp/8/6N1/8/8/8/1Q4K1 w - - 0 1", 1},
+	{"1k6/ppp5/8/8/8/8/5PPP/3R2K1 w - - 0 1", 1},
+	{"k7/8/2K5/8/8/8/8/7R w - - 0 1", 2},
+	{"6k1/pp4p1/2p5/2bp4/8/P5Pb/1P3rrP/2BRRN1K b - - 0 1", 2},
+	{"r5rk/5p1p/5R2/4B3/8/8/7P/7K w - - 0 1", 3},
+	{"r1b1kb1r/pppp1ppp/5q2/4n3/3KP3/2N3PN/PPP4P/R1BQ1B1R b kq - 0 1", 3},
+}
+
+func TestMateSuite(t *testing.T) {
+	for _, test := range mateSuite {
+		pos, _ := position.NewPositionFen(test.fen)
+		// the nominal depth to see a mate in n ends with the mating move
+		depth := 2*test.mateIn - 1
+		result := Search(context.Background(), pos, Limits{Depth: depth})
+		assert.Equal(t, MateScore-depth, result.Score, test.fen)
+		assert.Equal(t, depth, len(result.PV), test.fen)
+		for _, move := range result.PV {
+			assert.True(t, MakeValidMove(move, &pos), test.fen)
+		}
+		assert.True(t, pos.InCheck(), test.fen)
+		assert.Equal(t, 0, generate.GenerateLegalMoves(pos).Length(), test.fen)
+	}
+}
+
+func TestExtension(t *testing.T) {
+	pos, _ := position.NewPositionFen("6k1/5ppp/8/8/8/8/5PPP/3RR1K1 w - - 0 1")
+	p := SearchParams{Depth: 4, Pos: &pos, state: &searchState{}}
+	pos.MakeMoveAlgebraic("d1", "d8")
+	assert.Equal(t, 1, extension(20, p), "check")
+	pos.UnmakeMove()
+	pos.MakeMoveAlgebraic("g1", "h1")
+	assert.Equal(t, 0, extension(20, p))
+	assert.Equal(t, 1, extension(1, p), "only legal move")
+
+	capped := p.extended(2)
+	assert.Equal(t, 6, capped.Depth)
+	assert.Equal(t, 1, extension(1, capped), "two extensions in a search of depth four")
+	capped = capped.extended(1).reduced(1)
+	assert.Equal(t, 0, extension(1, capped), "no more extensions than the depth of the search")
+	p.Depth = maxPly
+	assert.Equal(t, 0, extension(1, p), "deepest ply")
+	assert.Equal(t, 0, extension(1, SearchParams{Depth: 4, Pos: &pos}), "no search state")
+}
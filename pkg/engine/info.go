@@ -0,0 +1,155 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tonyOreglia/glee/pkg/moves"
+)
+
+// infoInterval is how often progress is reported while searching
+const infoInterval = time.Second
+
+// Info reports the progress of a search.
+// It is sent after every completed iteration with the principal variation,
+// and periodically during long iterations with the node count and current root move.
+type Info struct {
+	Depth    int
+	SelDepth int
+	// Score is in centipawns from the point of view of the side to move, only set for a
+	// completed iteration or along with Bound. Mate scores are reported as the number of
+	// moves until mate.
+	Score int
+	// Completed marks the report of a completed iteration, it has a score even when the PV
+	// is empty because the root is mate or stalemate
+	Completed bool
+	// Bound is set when the score of the iteration fell outside the aspiration window
+	// and is searched again, the true score is at least or at most Score
+	Bound Bound
+	// Nodes includes QNodes, the nodes searched in quiescence search
+	Nodes  int
+	QNodes int
+	Time   time.Duration
+	// HashFull is the transposition table usage in permill, -1 when there is no table
+	HashFull       int
+	PV             []moves.Move
+	CurrMove       moves.Move
+	CurrMoveNumber int
+}
+
+// NPS returns the number of nodes searched per second
+func (i Info) NPS() int {
+	if i.Time <= 0 {
+		return 0
+	}
+	return int(int64(i.Nodes) * int64(time.Second) / int64(i.Time))
+}
+
+// String formats the info as the arguments of a UCI info command
+func (i Info) String() string {
+	var fields []string
+	if i.Depth > 0 {
+		fields = append(fields, fmt.Sprintf("depth %d", i.Depth))
+	}
+	if i.SelDepth > 0 {
+		fields = append(fields, fmt.Sprintf("seldepth %d", i.SelDepth))
+	}
+	if i.CurrMoveNumber > 0 {
+		fields = append(fields, fmt.Sprintf("currmove %s currmovenumber %d", i.CurrMove.String(), i.CurrMoveNumber))
+	}
+	if i.Completed || len(i.PV) > 0 || i.Bound != 0 {
+		if mateMoves, ok := mateIn(i.Score); ok {
+			fields = append(fields, fmt.Sprintf("score mate %d", mateMoves))
+		} else {
+			fields = append(fields, fmt.Sprintf("score cp %d", i.Score))
+		}
+		switch i.Bound {
+		case LowerBound:
+			fields = append(fields, "lowerbound")
+		case UpperBound:
+			fields = append(fields, "upperbound")
+		}
+	}
+	fields = append(fields, fmt.Sprintf("nodes %d nps %d time %d", i.Nodes, i.NPS(), i.Time.Milliseconds()))
+	if i.HashFull >= 0 {
+		fields = append(fields, fmt.Sprintf("hashfull %d", i.HashFull))
+	}
+	if len(i.PV) > 0 {
+		pv := make([]string, len(i.PV))
+		for index := range i.PV {
+			pv[index] = i.PV[index].String()
+		}
+		fields = append(fields, "pv "+strings.Join(pv, " "))
+	}
+	return strings.Join(fields, " ")
+}
+
+// mateIn converts a mate score into the number of moves until mate,
+// negative when the side to move is getting mated
+func mateIn(score int) (int, bool) {
+	switch {
+	case score > mateThreshold:
+		return (MateScore - score + 1) / 2, true
+	case score < -mateThreshold:
+		return -(MateScore + score) / 2, true
+	}
+	return 0, false
+}
+
+// reportIteration sends the result of a completed iteration
+func (s *searchState) reportIteration(result Result) {
+	if s.onInfo == nil {
+		return
+	}
+	info := s.progress()
+	info.Depth = result.Depth
+	info.Score = result.Score
+	info.Completed = true
+	info.PV = result.PV
+	s.onInfo(info)
+}
+
+// reportBound sends the score of an iteration that fell outside the aspiration window
+func (s *searchState) reportBound(depth int, score int, bound Bound) {
+	if s.onInfo == nil {
+		return
+	}
+	info := s.progress()
+	info.Depth = depth
+	info.Score = score
+	info.Bound = bound
+	s.onInfo(info)
+}
+
+// reportProgress periodically sends node counts during long iterations
+func (s *searchState) reportProgress() {
+	if s.onInfo == nil || time.Since(s.lastInfo) < infoInterval {
+		return
+	}
+	s.onInfo(s.progress())
+}
+
+// reportCurrMove sends the root move about to be searched once the search has been running a while
+func (s *searchState) reportCurrMove(depth int, move moves.Move, moveNumber int) {
+	if s == nil || s.onInfo == nil || time.Since(s.start) < infoInterval {
+		return
+	}
+	info := s.progress()
+	info.Depth = depth
+	info.SelDepth = 0
+	info.CurrMove = move
+	info.CurrMoveNumber = moveNumber
+	s.onInfo(info)
+}
+
+func (s *searchState) progress() Info {
+	s.lastInfo = time.Now()
+	return Info{
+		SelDepth: s.selDepth,
+		Nodes:    s.nodes,
+		QNodes:   s.qnodes,
+		Time:     time.Since(s.start),
+		HashFull: s.tt.Hashfull(),
+	}
+}
@@ -0,0 +1,102 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tonyOreglia/glee/pkg/moves"
+	"github.com/tonyOreglia/glee/pkg/position"
+)
+
+func TestInfoString(t *testing.T) {
+	info := Info{
+		Depth:    3,
+		SelDepth: 5,
+		Score:    -25,
+		Nodes:    2000,
+		Time:     500 * time.Millisecond,
+		HashFull: 12,
+		PV:       []moves.Move{*moves.NewMove([]int{52, 36}), *moves.NewMove([]int{12, 28})},
+	}
+	assert.Equal(t, "depth 3 seldepth 5 score cp -25 nodes 2000 nps 4000 time 500 hashfull 12 pv e2e4 e7e5", info.String())
+
+	info = Info{
+		Depth:          7,
+		Nodes:          100,
+		HashFull:       -1,
+		CurrMove:       *moves.NewMove([]int{62, 45}),
+		CurrMoveNumber: 4,
+	}
+	assert.Equal(t, "depth 7 currmove g1f3 currmovenumber 4 nodes 100 nps 0 time 0", info.String())
+
+	info = Info{Depth: 2, Score: MateScore - 3, HashFull: -1, PV: []moves.Move{*moves.NewMove([]int{56, 0})}}
+	assert.Equal(t, "depth 2 score mate 2 nodes 0 nps 0 time 0 pv a1a8", info.String())
+	info.Score = -MateScore + 4
+	assert.Equal(t, "depth 2 score mate -2 nodes 0 nps 0 time 0 pv a1a8", info.String())
+
+	info = Info{Depth: 1, Score: -MateScore, HashFull: -1, Completed: true}
+	assert.Equal(t, "depth 1 score mate 0 nodes 0 nps 0 time 0", info.String(), "mated at the root")
+	info.Score = 0
+	assert.Equal(t, "depth 1 score cp 0 nodes 0 nps 0 time 0", info.String(), "stalemate")
+
+	info = Info{Depth: 5, Score: 120, HashFull: -1, Bound: LowerBound}
+	assert.Equal(t, "depth 5 score cp 120 lowerbound nodes 0 nps 0 time 0", info.String())
+	info.Bound = UpperBound
+	assert.Equal(t, "depth 5 score cp 120 upperbound nodes 0 nps 0 time 0", info.String())
+}
+
+func TestSearchReportsEveryIteration(t *testing.T) {
+	var infos []Info
+	limits := Limits{
+		Depth: 3,
+		TT:    NewTranspositionTable(1),
+		OnInfo: func(info Info) {
+			infos = append(infos, info)
+		},
+	}
+	result := Search(context.Background(), position.StartingPosition(), limits)
+	assert.Equal(t, 3, len(infos))
+	for i, info := range infos {
+		assert.Equal(t, i+1, info.Depth)
+		assert.Equal(t, i+1, len(info.PV))
+		assert.True(t, info.HashFull >= 0)
+	}
+	assert.Equal(t, result.PV, infos[2].PV)
+	assert.Equal(t, result.Nodes, infos[2].Nodes)
+}
+
+func TestProgressIsReportedDuringFirstIteration(t *testing.T) {
+	var infos []Info
+	state := &searchState{
+		nodes:    1024,
+		start:    time.Now().Add(-2 * infoInterval),
+		lastInfo: time.Now().Add(-2 * infoInterval),
+		onInfo: func(info Info) {
+			infos = append(infos, info)
+		},
+	}
+	assert.False(t, state.shouldStop(), "the first iteration can not stop")
+	if assert.Equal(t, 1, len(infos)) {
+		assert.Equal(t, 1024, infos[0].Nodes)
+		assert.Empty(t, infos[0].PV)
+	}
+}
+
+func TestSearchReportsScoreWithoutMove(t *testing.T) {
+	tests := map[string]string{
+		"R5k1/5ppp/8/8/8/8/8/6K1 b - - 0 1": "score mate 0",
+		"7k/5Q2/6K1/8/8/8/8/8 b - - 0 1":    "score cp 0",
+	}
+	for fen, score := range tests {
+		var infos []Info
+		pos, _ := position.NewPositionFen(fen)
+		Search(context.Background(), pos, Limits{Depth: 3, OnInfo: func(info Info) {
+			infos = append(infos, info)
+		}})
+		if assert.Equal(t, 1, len(infos), fen) {
+			assert.Contains(t, infos[0].String(), score, fen)
+		}
+	}
+}
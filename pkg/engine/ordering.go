@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"sort"
+
+	"github.com/tonyOreglia/glee/pkg/generate"
+	"github.com/tonyOreglia/glee/pkg/moves"
+	"github.com/tonyOreglia/glee/pkg/position"
+)
+
+// Move ordering scores. The hash move is searched first, then captures and promotions
+// by MVV-LVA, then the killer moves of the ply and last the quiet moves by history score.
+const (
+	hashMoveScore = 1 << 30
+	tacticalScore = 1 << 28
+	killerScore   = 1 << 27
+	// maxHistory keeps history scores below the killer moves
+	maxHistory = killerScore / 2
+)
+
+// scoredMove is a move with its ordering score
+type scoredMove struct {
+	move  moves.Move
+	score int
+}
+
+// orderMoves sorts mvs best first for the search at height, hashMove is the best move
+// stored in the transposition table or zero. state may be nil, then there are no
+// killer moves or history scores.
+func (s *searchState) orderMoves(pos *position.Position, mvs []moves.Move, hashMove moves.Move, height int) {
+	scored := make([]scoredMove, len(mvs))
+	for i, move := range mvs {
+		scored[i] = scoredMove{move, s.moveScore(pos, move, hashMove, height)}
+	}
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].score > scored[j].score
+	})
+	for i := range scored {
+		mvs[i] = scored[i].move
+	}
+}
+
+func (s *searchState) moveScore(pos *position.Position, move moves.Move, hashMove moves.Move, height int) int {
+	if hashMove != 0 && move.SameSquares(hashMove) {
+		return hashMoveScore
+	}
+	if score, ok := mvvLva(pos, move); ok {
+		return tacticalScore + score
+	}
+	if s == nil {
+		return 0
+	}
+	switch {
+	case move.SameSquares(s.killers[height][0]):
+		return killerScore + 1
+	case move.SameSquares(s.killers[height][1]):
+		return killerScore
+	}
+	return s.history[pos.GetActiveSide()][move.Origin()][move.Destination()]
+}
+
+// isKiller reports whether move is one of the killer moves at height
+func (s *searchState) isKiller(height int, move moves.Move) bool {
+	return s != nil && (move.SameSquares(s.killers[height][0]) || move.SameSquares(s.killers[height][1]))
+}
+
+// storeCutoff remembers a quiet move that caused a beta cutoff at height as a killer move
+// and raises its history score by the square of the remaining depth, so cutoffs far from
+// the leaves count most. Captures and promotions are ordered by MVV-LVA already.
+func (s *searchState) storeCutoff(pos *position.Position, move moves.Move, height int, depth int) {
+	if s == nil || move.IsCapture() || move.PromotionPiece() != 0 {
+		return
+	}
+	if !move.SameSquares(s.killers[height][0]) {
+		s.killers[height][1] = s.killers[height][0]
+		s.killers[height][0] = move
+	}
+	history := &s.history[pos.GetActiveSide()]
+	history[move.Origin()][move.Destination()] += depth * depth
+	if history[move.Origin()][move.Destination()] > maxHistory {
+		// halving every score keeps the order while making room for new cutoffs
+		for origin := range history {
+			for dest := range history[origin] {
+				history[origin][dest] /= 2
+			}
+		}
+	}
+}
+
+// OrderedMoves returns the legal moves of pos in the order the search tries them,
+// with the best move stored for pos in tt first. tt may be nil.
+func OrderedMoves(pos *position.Position, tt *TranspositionTable) []moves.Move {
+	mvs := generate.GenerateLegalMoves(pos).GetMovesList()
+	var state *searchState
+	state.orderMoves(pos, mvs, hashMove(tt, pos.Hash()), 0)
+	return mvs
+}
+
+// hashMove returns the best move stored for hash, or zero
+func hashMove(tt *TranspositionTable, hash uint64) moves.Move {
+	entry, _ := tt.Probe(hash)
+	return entry.Move
+}
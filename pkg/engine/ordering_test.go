@@ -0,0 +1,81 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tonyOreglia/glee/pkg/generate"
+	"github.com/tonyOreglia/glee/pkg/moves"
+	"github.com/tonyOreglia/glee/pkg/position"
+)
+
+// orderingFen has four captures for white: bxc4, exd5, Nxd5 and Qxd5
+const orderingFen = "4k3/8/8/3p4/2q1P3/1PN5/8/3QK3 w - - 0 1"
+
+func TestOrderMovesCapturesByMvvLva(t *testing.T) {
+	pos, _ := position.NewPositionFen(orderingFen)
+	mvs := generate.GenerateLegalMoves(pos).GetMovesList()
+	var state *searchState
+	state.orderMoves(pos, mvs, 0, 0)
+	assert.Equal(t, []string{"b3c4", "e4d5", "c3d5", "d1d5"}, moveStrings(mvs[:4]))
+}
+
+func TestOrderMovesHashMoveFirst(t *testing.T) {
+	pos, _ := position.NewPositionFen(orderingFen)
+	tt := NewTranspositionTable(1)
+	kf2, _ := generate.GenerateLegalMoves(pos).FindMove(60, 53, 0)
+	tt.Store(pos.Hash(), 3, 0, ExactBound, kf2)
+	mvs := OrderedMoves(pos, tt)
+	assert.Equal(t, []string{"e1f2", "b3c4", "e4d5"}, moveStrings(mvs[:3]))
+	assert.Equal(t, "b3c4", OrderedMoves(pos, nil)[0].String())
+}
+
+func TestOrderMovesKillersBeforeHistory(t *testing.T) {
+	pos, _ := position.NewPositionFen(orderingFen)
+	legal := generate.GenerateLegalMoves(pos)
+	kf2, _ := legal.FindMove(60, 53, 0)
+	nb5, _ := legal.FindMove(42, 25, 0)
+	e5, _ := legal.FindMove(36, 28, 0)
+	state := &searchState{}
+	state.storeCutoff(pos, e5, 2, 1)
+	state.storeCutoff(pos, e5, 2, 1)
+	state.storeCutoff(pos, nb5, 3, 4)
+	state.storeCutoff(pos, kf2, 2, 1)
+
+	mvs := legal.GetMovesList()
+	state.orderMoves(pos, mvs, 0, 2)
+	assert.Equal(t, []string{"e1f2", "e4e5", "c3b5"}, moveStrings(mvs[4:7]), "killers then history")
+
+	state.orderMoves(pos, mvs, 0, 3)
+	assert.Equal(t, []string{"c3b5", "e4e5", "e1f2"}, moveStrings(mvs[4:7]), "killers are kept per height")
+}
+
+func TestStoreCutoffIgnoresCaptures(t *testing.T) {
+	pos, _ := position.NewPositionFen(orderingFen)
+	bxc4, _ := generate.GenerateLegalMoves(pos).FindMove(41, 34, 0)
+	state := &searchState{}
+	state.storeCutoff(pos, bxc4, 0, 3)
+	assert.Equal(t, moves.Move(0), state.killers[0][0])
+	assert.Equal(t, 0, state.history[position.White][41][34])
+}
+
+func TestHistoryIsHalvedWhenFull(t *testing.T) {
+	pos, _ := position.NewPositionFen(orderingFen)
+	legal := generate.GenerateLegalMoves(pos)
+	kf2, _ := legal.FindMove(60, 53, 0)
+	e5, _ := legal.FindMove(36, 28, 0)
+	state := &searchState{}
+	state.history[position.White][36][28] = 10
+	state.history[position.White][60][53] = maxHistory
+	state.storeCutoff(pos, kf2, 0, 2)
+	assert.Equal(t, (maxHistory+4)/2, state.history[position.White][60][53])
+	assert.Equal(t, 5, state.history[position.White][e5.Origin()][e5.Destination()])
+}
+
+func moveStrings(mvs []moves.Move) []string {
+	var result []string
+	for _, mv := range mvs {
+		result = append(result, mv.String())
+	}
+	return result
+}
@@ -0,0 +1,53 @@
+package engine
+
+import (
+	"sort"
+
+	"github.com/tonyOreglia/glee/pkg/generate"
+	"github.com/tonyOreglia/glee/pkg/moves"
+	"github.com/tonyOreglia/glee/pkg/position"
+)
+
+// DivideCount is the number of leaf nodes found below a single root move
+type DivideCount struct {
+	Move  moves.Move
+	Nodes int
+}
+
+// Perft counts the leaf nodes of the legal move tree depth plies below pos.
+// Positions are not evaluated, the count only depends on move generation.
+func Perft(pos *position.Position, depth int) int {
+	if depth == 0 {
+		return 1
+	}
+	mvs := generate.GenerateLegalMoves(pos).GetMovesList()
+	// the moves at the last ply are the leaves, there is no need to make them
+	if depth == 1 {
+		return len(mvs)
+	}
+	nodes := 0
+	for _, move := range mvs {
+		pos.Move(move)
+		nodes += Perft(pos, depth-1)
+		pos.UnmakeMove()
+	}
+	return nodes
+}
+
+// Divide counts the leaf nodes below every legal move of pos, sorted by move.
+// Comparing the counts with another engine narrows a move generation bug down to a single move.
+func Divide(pos *position.Position, depth int) []DivideCount {
+	var counts []DivideCount
+	if depth < 1 {
+		return counts
+	}
+	for _, move := range generate.GenerateLegalMoves(pos).GetMovesList() {
+		pos.Move(move)
+		counts = append(counts, DivideCount{Move: move, Nodes: Perft(pos, depth-1)})
+		pos.UnmakeMove()
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		return counts[i].Move.String() < counts[j].Move.String()
+	})
+	return counts
+}
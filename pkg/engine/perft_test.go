@@ -0,0 +1,39 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tonyOreglia/glee/pkg/position"
+)
+
+func TestPerft(t *testing.T) {
+	for _, tt := range flagtests {
+		pos, _ := position.NewPositionFen(tt.fen)
+		assert.Equal(t, tt.expectedNodes, Perft(pos, tt.depth), tt.name)
+		assert.Equal(t, tt.fen, pos.GetFenString(), "position is restored")
+	}
+	assert.Equal(t, 1, Perft(position.StartingPosition(), 0))
+}
+
+func TestDivide(t *testing.T) {
+	pos := position.StartingPosition()
+	counts := Divide(pos, 2)
+	assert.Equal(t, 20, len(counts))
+	assert.Equal(t, "a2a3", counts[0].Move.String())
+	assert.Equal(t, "h2h4", counts[len(counts)-1].Move.String())
+	for _, count := range counts {
+		assert.Equal(t, 20, count.Nodes, count.Move.String())
+	}
+
+	pos, _ = position.NewPositionFen("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+	total := 0
+	for _, count := range Divide(pos, 3) {
+		total += count.Nodes
+		if count.Move.String() == "e1g1" {
+			assert.Equal(t, 2059, count.Nodes, "castling kingside")
+		}
+	}
+	assert.Equal(t, 97862, total)
+	assert.Empty(t, Divide(pos, 0))
+}
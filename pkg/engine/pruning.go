@@ -0,0 +1,77 @@
+package engine
+
+import (
+	"github.com/tonyOreglia/glee/pkg/moves"
+	"github.com/tonyOreglia/glee/pkg/position"
+)
+
+const (
+	// nullMoveReduction is how much shallower the position is searched after a null move
+	nullMoveReduction = 2
+	// nullMoveMinDepth is the least remaining depth at which a null move is tried
+	nullMoveMinDepth = 3
+	// lateMoveMinDepth is the least remaining depth at which late moves are reduced
+	lateMoveMinDepth = 3
+	// lateMoveNumber is the first move in the ordered list that may be reduced
+	lateMoveNumber = 4
+)
+
+// reduced returns p for a child searched r plies shallower. Depth is lowered
+// with the remaining depth so that the height of the child, Depth - ply, is right.
+func (p SearchParams) reduced(r int) SearchParams {
+	p.Depth -= r
+	return p
+}
+
+// nullMovePrunes lets the opponent move twice in a row with a reduced search. When the side
+// to move still scores at least beta the position is good enough to cut off without
+// searching its moves. Only nodes outside the principal variation are pruned, and not
+// when the side to move is in check or has nothing but pawns, where having to move
+// can be a disadvantage (zugzwang) and passing would be better than any move.
+func nullMovePrunes(alpha int, beta int, ply int, inCheck bool, p SearchParams) bool {
+	if !p.state.nullMoveEnabled() || p.Root || p.afterNullMove || inCheck || ply < nullMoveMinDepth ||
+		beta-alpha != 1 || beta >= mateThreshold || !hasPieces(*p.Pos) {
+		return false
+	}
+	r := nullMoveReduction
+	if r > ply-1 {
+		r = ply - 1
+	}
+	child := p.reduced(r)
+	child.afterNullMove = true
+	(*p.Pos).MakeNullMove()
+	score := -AlphaBeta(-beta, -beta+1, ply-1-r, child)
+	(*p.Pos).UnmakeMove()
+	return score >= beta && !p.state.shouldStop()
+}
+
+// lateMoveReduction returns how many plies shallower move is searched. move has just been made.
+// Quiet moves that come late in the ordered list rarely turn out best and are reduced by
+// a ply, unless the position was or is now a check.
+func lateMoveReduction(ply int, height int, moveNumber int, move moves.Move, inCheck bool, p SearchParams) int {
+	if !p.state.reductionsEnabled() || p.Root || ply < lateMoveMinDepth || moveNumber < lateMoveNumber ||
+		inCheck || move.IsCapture() || move.PromotionPiece() != 0 || p.state.isKiller(height, move) ||
+		(*p.Pos).InCheck() {
+		return 0
+	}
+	return 1
+}
+
+// hasPieces reports whether the side to move has a piece other than the king and pawns
+func hasPieces(pos *position.Position) bool {
+	bitboards := pos.GetActiveSidesBitboards()
+	for piece := position.Queen; piece <= position.Rooks; piece++ {
+		if !bitboards[piece].IsZero() {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *searchState) nullMoveEnabled() bool {
+	return s != nil && s.nullMove
+}
+
+func (s *searchState) reductionsEnabled() bool {
+	return s != nil && s.reductions
+}
@@ -0,0 +1,81 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tonyOreglia/glee/pkg/generate"
+	"github.com/tonyOreglia/glee/pkg/moves"
+	"github.com/tonyOreglia/glee/pkg/position"
+)
+
+func TestNullMovePrunes(t *testing.T) {
+	// white is a queen up, passing the turn still scores above beta
+	fen := "4k3/pppp4/8/8/8/8/PPPP4/3QK3 w - - 0 1"
+	pos, _ := position.NewPositionFen(fen)
+	state := &searchState{nullMove: true}
+	p := SearchParams{Depth: 4, Pos: &pos, state: state}
+	assert.True(t, nullMovePrunes(0, 1, 3, false, p))
+	assert.Equal(t, fen, pos.GetFenString(), "null move is taken back")
+
+	assert.False(t, nullMovePrunes(0, 1, 3, true, p), "in check")
+	assert.False(t, nullMovePrunes(0, 1, 2, false, p), "too shallow")
+	assert.False(t, nullMovePrunes(-1, 1, 3, false, p), "principal variation")
+	assert.False(t, nullMovePrunes(2000, 2001, 3, false, p), "passing is not good enough")
+	after := p
+	after.afterNullMove = true
+	assert.False(t, nullMovePrunes(0, 1, 3, false, after), "two null moves in a row")
+	assert.False(t, nullMovePrunes(0, 1, 3, false, SearchParams{Depth: 4, Pos: &pos}), "turned off")
+
+	// only pawns left, the side to move may be in zugzwang
+	pos, _ = position.NewPositionFen("4k3/pppp4/8/8/8/8/PPPP4/4K3 w - - 0 1")
+	assert.False(t, nullMovePrunes(-1000, -999, 3, false, SearchParams{Depth: 4, Pos: &pos, state: state}))
+}
+
+func TestLateMoveReduction(t *testing.T) {
+	pos, _ := position.NewPositionFen(orderingFen)
+	legal := generate.GenerateLegalMoves(pos)
+	state := &searchState{reductions: true}
+	p := SearchParams{Depth: 5, Pos: &pos, state: state}
+	reduction := func(move moves.Move, ply int, moveNumber int, inCheck bool, p SearchParams) int {
+		pos.Move(move)
+		defer pos.UnmakeMove()
+		return lateMoveReduction(ply, p.Depth-ply, moveNumber, move, inCheck, p)
+	}
+	kf2, _ := legal.FindMove(60, 53, 0)
+	bxc4, _ := legal.FindMove(41, 34, 0)
+	qh5, _ := legal.FindMove(59, 31, 0)
+	assert.Equal(t, 1, reduction(kf2, 4, 10, false, p))
+	assert.Equal(t, 0, reduction(kf2, 4, 2, false, p), "searched early")
+	assert.Equal(t, 0, reduction(kf2, 2, 10, false, p), "too shallow")
+	assert.Equal(t, 0, reduction(kf2, 4, 10, true, p), "in check")
+	assert.Equal(t, 0, reduction(bxc4, 4, 10, false, p), "capture")
+	assert.Equal(t, 0, reduction(qh5, 4, 10, false, p), "gives check")
+	state.storeCutoff(pos, kf2, 1, 4)
+	assert.Equal(t, 0, reduction(kf2, 4, 10, false, p), "killer move")
+	assert.Equal(t, 0, reduction(kf2, 4, 10, false, SearchParams{Depth: 5, Pos: &pos}), "turned off")
+}
+
+func TestSelectiveSearchKeepsResult(t *testing.T) {
+	fens := []string{
+		// back rank mate
+		"6k1/5ppp/8/8/8/8/5PPP/3RR1K1 w - - 0 1",
+		// king and pawn ending, where null moves are not tried
+		"8/8/8/2k5/8/2K5/2P5/8 w - - 0 1",
+	}
+	for _, fen := range fens {
+		pos, _ := position.NewPositionFen(fen)
+		full := Search(context.Background(), pos, Limits{Depth: 5, DisableNullMove: true, DisableLateMoveReductions: true})
+		selective := Search(context.Background(), pos, Limits{Depth: 5})
+		assert.Equal(t, full.Score, selective.Score, fen)
+		assert.True(t, selective.Nodes <= full.Nodes, fen)
+	}
+}
+
+func TestHasPieces(t *testing.T) {
+	pos, _ := position.NewPositionFen("4k3/pppp4/8/8/8/8/PPPP4/3NK3 b - - 0 1")
+	assert.False(t, hasPieces(pos))
+	pos, _ = position.NewPositionFen("4k3/pppp4/8/8/8/8/PPPP4/3NK3 w - - 0 1")
+	assert.True(t, hasPieces(pos))
+}
@@ -0,0 +1,62 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tonyOreglia/glee/pkg/moves"
+	"github.com/tonyOreglia/glee/pkg/position"
+)
+
+// twinsResults are the scores, from the point of view of the side to move, and the best
+// moves found by AlphaBetaMax and AlphaBetaMin, the white maximizing and black minimizing
+// searches AlphaBeta replaced, with the full window and no transposition table. Their
+// quiescence searches were given the same evasions in check as Quiesce.
+var twinsResults = []struct {
+	fen      string
+	depth    int
+	score    int
+	bestMove string
+}{
+	{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", 1, 50, "b1c3"},
+	{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", 2, 0, "b1c3"},
+	{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", 3, 50, "b1c3"},
+	{"r1bqkbnr/pppp1ppp/2n5/4p3/3PP3/5N2/PPP2PPP/RNBQKB1R b KQkq - 0 3", 1, 73, "g8f6"},
+	{"r1bqkbnr/pppp1ppp/2n5/4p3/3PP3/5N2/PPP2PPP/RNBQKB1R b KQkq - 0 3", 2, 23, "e5d4"},
+	{"r1bqkbnr/pppp1ppp/2n5/4p3/3PP3/5N2/PPP2PPP/RNBQKB1R b KQkq - 0 3", 3, 53, "e5d4"},
+	{"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", 1, 142, "e2a6"},
+	{"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", 2, 142, "e2a6"},
+	{"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", 3, 122, "d5e6"},
+	{"n1n5/PPPk4/8/8/8/8/4Kppp/5N1N b - - 0 1", 1, 495, "g2h1q"},
+	{"n1n5/PPPk4/8/8/8/8/4Kppp/5N1N b - - 0 1", 2, 495, "g2h1q"},
+	{"n1n5/PPPk4/8/8/8/8/4Kppp/5N1N b - - 0 1", 3, 870, "g2h1q"},
+	{"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1", 1, 120, "b4f4"},
+	{"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1", 2, 120, "b4f4"},
+	{"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1", 3, 80, "b4f4"},
+	{"6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1", 1, 29999, "a1a8"},
+	{"6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1", 2, 29999, "a1a8"},
+	{"6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1", 3, 29999, "a1a8"},
+	{"3r2k1/8/8/8/3N4/8/8/6K1 b - - 0 1", 1, 510, "d8d4"},
+	{"3r2k1/8/8/8/3N4/8/8/6K1 b - - 0 1", 2, 500, "d8d4"},
+	{"3r2k1/8/8/8/3N4/8/8/6K1 b - - 0 1", 3, 510, "d8d4"},
+}
+
+func TestAlphaBetaMatchesMaxMinTwins(t *testing.T) {
+	for _, test := range twinsResults {
+		pos, _ := position.NewPositionFen(test.fen)
+		var move moves.Move
+		params := SearchParams{Depth: test.depth, Pos: &pos, EngineMove: &move}
+		assert.Equal(t, test.score, AlphaBeta(-infinity, infinity, test.depth, params), "%s depth %d", test.fen, test.depth)
+		assert.Equal(t, test.bestMove, move.String(), "%s depth %d", test.fen, test.depth)
+		assert.Equal(t, test.fen, pos.GetFenString(), "position is restored")
+	}
+}
+
+func TestAlphaBetaWithSearchState(t *testing.T) {
+	// killers and history change the order the moves are tried in but not the score
+	fen := "r1bqkbnr/pppp1ppp/2n5/4p3/3PP3/5N2/PPP2PPP/RNBQKB1R b KQkq - 0 3"
+	pos, _ := position.NewPositionFen(fen)
+	var mv moves.Move
+	score := AlphaBeta(-10000, 10000, 3, SearchParams{Depth: 3, Pos: &pos, EngineMove: &mv, state: &searchState{}})
+	assert.Equal(t, alphaBetaScore(fen, 3, nil), score)
+}
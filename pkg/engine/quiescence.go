@@ -0,0 +1,124 @@
+package engine
+
+import (
+	"sort"
+
+	"github.com/tonyOreglia/glee/pkg/evaluate"
+	"github.com/tonyOreglia/glee/pkg/generate"
+	"github.com/tonyOreglia/glee/pkg/moves"
+	"github.com/tonyOreglia/glee/pkg/position"
+)
+
+// mvvLvaValues ranks pieces by value for move ordering, indexed by piece
+var mvvLvaValues = [7]int{
+	position.King:    6,
+	position.Queen:   5,
+	position.Rooks:   4,
+	position.Bishops: 3,
+	position.Knights: 2,
+	position.Pawns:   1,
+}
+
+// Quiesce extends the search past the horizon with captures and promotions
+// until the position is quiet, so that the evaluation is not taken in the middle
+// of an exchange. The side to move may stand pat, unless it is in check, then every
+// evasion is searched so that a mate past the horizon is found. The score is from the
+// point of view of the side to move.
+func Quiesce(alpha int, beta int, height int, p SearchParams) int {
+	p.state.visitQuiescenceNode(height)
+	if p.state.shouldStop() || isDraw(*p.Pos) {
+		return 0
+	}
+	if (*p.Pos).InCheck() && height < maxPly {
+		return quiesceEvasions(alpha, beta, height, p)
+	}
+	standPat := evaluateSideToMove(*p.Pos)
+	if height >= maxPly {
+		return standPat
+	}
+	if standPat >= beta {
+		return beta
+	}
+	if standPat > alpha {
+		alpha = standPat
+	}
+	for _, move := range tacticalMoves(*p.Pos) {
+		(*p.Pos).Move(move)
+		score := -Quiesce(-beta, -alpha, height+1, p)
+		(*p.Pos).UnmakeMove()
+		if p.state.shouldStop() {
+			return 0
+		}
+		if score >= beta {
+			return beta
+		}
+		if score > alpha {
+			alpha = score
+		}
+	}
+	return alpha
+}
+
+// quiesceEvasions searches every legal move of a position in check, tactical moves first
+func quiesceEvasions(alpha int, beta int, height int, p SearchParams) int {
+	mvs := generate.GenerateLegalMoves(*p.Pos).GetMovesList()
+	if len(mvs) == 0 {
+		return -MateScore + height
+	}
+	p.state.orderMoves(*p.Pos, mvs, 0, height)
+	for _, move := range mvs {
+		(*p.Pos).Move(move)
+		score := -Quiesce(-beta, -alpha, height+1, p)
+		(*p.Pos).UnmakeMove()
+		if p.state.shouldStop() {
+			return 0
+		}
+		if score >= beta {
+			return beta
+		}
+		if score > alpha {
+			alpha = score
+		}
+	}
+	return alpha
+}
+
+// evaluateSideToMove returns the evaluation of pos from the point of view of the side to move
+func evaluateSideToMove(pos *position.Position) int {
+	if pos.IsWhitesTurn() {
+		return evaluate.EvaluatePosition(pos)
+	}
+	return -evaluate.EvaluatePosition(pos)
+}
+
+// tacticalMoves returns the legal captures and promotions of pos,
+// most valuable victim first and then least valuable attacker first
+func tacticalMoves(pos *position.Position) []moves.Move {
+	var scored []scoredMove
+	for _, move := range generate.GenerateLegalMoves(pos).GetMovesList() {
+		if score, ok := mvvLva(pos, move); ok {
+			scored = append(scored, scoredMove{move, score})
+		}
+	}
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].score > scored[j].score
+	})
+	mvs := make([]moves.Move, len(scored))
+	for i := range scored {
+		mvs[i] = scored[i].move
+	}
+	return mvs
+}
+
+// mvvLva scores a capture or promotion for ordering, ok is false for quiet moves
+func mvvLva(pos *position.Position, move moves.Move) (score int, ok bool) {
+	if !move.IsCapture() && move.PromotionPiece() == 0 {
+		return 0, false
+	}
+	attacker, _ := pos.PieceAt(move.Origin())
+	score = mvvLvaValues[move.CapturedPiece()]*8 - mvvLvaValues[attacker]
+	if move.PromotionPiece() != 0 {
+		score += mvvLvaValues[move.PromotionPiece()] * 8
+	}
+	return score, true
+}
@@ -0,0 +1,63 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tonyOreglia/glee/pkg/evaluate"
+	"github.com/tonyOreglia/glee/pkg/position"
+)
+
+func TestQuiescenceAvoidsDefendedPawn(t *testing.T) {
+	// the pawn on e5 is defended, taking it loses the queen
+	pos, _ := position.NewPositionFen("4k3/8/3p4/4p3/8/8/4Q3/4K3 w - - 0 1")
+	result := Search(context.Background(), pos, Limits{Depth: 1})
+	assert.NotEqual(t, "e2e5", result.BestMove.String())
+	assert.True(t, result.QNodes > 0)
+	assert.True(t, result.Nodes > result.QNodes)
+}
+
+func TestQuiescenceStandsPatInQuietPosition(t *testing.T) {
+	pos := position.StartingPosition()
+	state := &searchState{}
+	score := Quiesce(-infinity, infinity, 0, SearchParams{Pos: &pos, state: state})
+	assert.Equal(t, evaluate.EvaluatePosition(pos), score)
+	assert.Equal(t, 1, state.qnodes)
+}
+
+func TestQuiescenceResolvesExchange(t *testing.T) {
+	// black to move can win the undefended knight on d4
+	pos, _ := position.NewPositionFen("3r2k1/8/8/8/3N4/8/8/6K1 b - - 0 1")
+	score := Quiesce(-infinity, infinity, 0, SearchParams{Pos: &pos, state: &searchState{}})
+	assert.True(t, score > -evaluate.EvaluatePosition(pos)+250, "knight is won")
+}
+
+func TestQuiescenceSearchesEvasionsInCheck(t *testing.T) {
+	// black is mated on the back rank, standing pat would score it as a rook down
+	pos, _ := position.NewPositionFen("R5k1/5ppp/8/8/8/8/8/6K1 b - - 0 1")
+	assert.Equal(t, -MateScore+3, Quiesce(-infinity, infinity, 3, SearchParams{Pos: &pos, state: &searchState{}}))
+
+	// the knight forks king and queen, black cannot stand pat a queen up
+	pos, _ = position.NewPositionFen("3q3k/p4N2/8/8/8/8/8/K7 b - - 0 1")
+	score := Quiesce(-infinity, infinity, 0, SearchParams{Pos: &pos, state: &searchState{}})
+	assert.True(t, score < 0, "the queen is lost after the king moves")
+}
+
+func TestTacticalMovesOrder(t *testing.T) {
+	// the pawn can take the queen or the knight
+	pos, _ := position.NewPositionFen("4k3/8/8/2n1q3/3P4/8/8/R5K1 w - - 0 1")
+	mvs := tacticalMoves(pos)
+	assert.Equal(t, 2, len(mvs))
+	assert.Equal(t, "d4e5", mvs[0].String(), "most valuable victim first")
+	assert.Equal(t, "d4c5", mvs[1].String())
+
+	pos, _ = position.NewPositionFen("4k3/8/8/4q3/3P4/8/8/4R1K1 w - - 0 1")
+	mvs = tacticalMoves(pos)
+	assert.Equal(t, 2, len(mvs))
+	assert.Equal(t, "d4e5", mvs[0].String(), "least valuable attacker first")
+	assert.Equal(t, "e1e5", mvs[1].String())
+
+	pos, _ = position.NewPositionFen("4k3/P7/8/8/8/8/8/4K3 w - - 0 1")
+	assert.Equal(t, 4, len(tacticalMoves(pos)), "promotions are tactical")
+}
@@ -0,0 +1,329 @@
+package engine
+
+import (
+	"context"
+	"time"
+
+	"github.com/tonyOreglia/glee/pkg/moves"
+	"github.com/tonyOreglia/glee/pkg/position"
+)
+
+// DefaultSearchDepth is used when no depth limit is given
+const DefaultSearchDepth = 5
+
+// maxPly is the deepest the search can go from the root
+const maxPly = 64
+
+// infinity bounds the alpha beta window at the root
+const infinity = 32000
+
+// MateScore is the score for giving mate at the root. A mate found n plies
+// from the root scores MateScore - n so that shorter mates score higher.
+const MateScore = 30000
+
+// aspirationWindow is how far the score of an iteration may be from the previous one
+// without searching it again, aspirationMinDepth the first iteration to use the window
+const (
+	aspirationWindow   = 50
+	aspirationMinDepth = 4
+)
+
+// mateThreshold separates mate scores from evaluations
+const mateThreshold = MateScore - maxPly - 1
+
+// Limits bounds a call to Search
+type Limits struct {
+	// Depth is the deepest iteration to search. When zero the search is bounded
+	// by the other limits, or by DefaultSearchDepth if there are none.
+	Depth int
+	// Nodes stops the search after this many nodes
+	Nodes int
+	// MoveTime is the exact time to spend on the move
+	MoveTime time.Duration
+	// WhiteTime, BlackTime, WhiteIncrement, BlackIncrement and MovesToGo describe the clock
+	WhiteTime      time.Duration
+	BlackTime      time.Duration
+	WhiteIncrement time.Duration
+	BlackIncrement time.Duration
+	MovesToGo      int
+	// Infinite searches until ctx is done
+	Infinite bool
+	// Ponder ignores the clock until PonderHit is closed, from then on
+	// the time limits apply as if the search had just started
+	Ponder    bool
+	PonderHit <-chan struct{}
+	// SearchMoves restricts the root moves searched when not empty
+	SearchMoves []moves.Move
+	// TT caches results between iterations and searches, optional
+	TT *TranspositionTable
+	// OnInfo receives progress reports while searching, optional
+	OnInfo func(Info)
+	// DisableNullMove and DisableLateMoveReductions turn off the selective search,
+	// to measure what it is worth
+	DisableNullMove           bool
+	DisableLateMoveReductions bool
+}
+
+// Result is the outcome of the deepest completed iteration
+type Result struct {
+	BestMove moves.Move
+	// Score is in centipawns from the point of view of the side to move,
+	// or MateScore less the number of plies to mate
+	Score int
+	Depth int
+	// PV is the principal variation starting with BestMove
+	PV []moves.Move
+	// Nodes counts every node searched, QNodes the part of them in quiescence search
+	Nodes  int
+	QNodes int
+}
+
+// searchState holds data shared by every node of a single search
+type searchState struct {
+	nodes  int
+	qnodes int
+	pv     [maxPly + 1][maxPly + 1]moves.Move
+	pvLen  [maxPly + 1]int
+	// killers are the last two quiet moves that caused a beta cutoff at each height,
+	// history scores quiet moves by side, origin and destination
+	killers [maxPly + 1][2]moves.Move
+	history [2][64][64]int
+
+	selDepth int
+	tt       *TranspositionTable
+	onInfo   func(Info)
+	lastInfo time.Time
+
+	ctx   context.Context
+	start time.Time
+	// clockStart is when the time limits started to apply
+	clockStart  time.Time
+	softLimit   time.Duration
+	hardLimit   time.Duration
+	nodeLimit   int
+	searchMoves []moves.Move
+	pondering   bool
+	ponderHit   <-chan struct{}
+	// canStop is false during the first iteration so there is always a move to play
+	canStop bool
+	stopped bool
+	// nullMove and reductions enable null move pruning and late move reductions
+	nullMove   bool
+	reductions bool
+}
+
+// Search runs an iterative deepening alpha beta search from depth 1 upward until
+// limits are reached, ctx is done or the root turns out to be mate or stalemate. The first
+// iteration always completes, an iteration that is interrupted is discarded.
+// pos is left unchanged.
+func Search(ctx context.Context, pos *position.Position, limits Limits) Result {
+	maxDepth := limits.Depth
+	if maxDepth <= 0 {
+		maxDepth = DefaultSearchDepth
+		if limits.hasTimeLimit(pos.GetActiveSide()) || limits.Nodes > 0 || limits.Infinite || limits.Ponder {
+			maxDepth = maxPly
+		}
+	}
+	if maxDepth > maxPly {
+		maxDepth = maxPly
+	}
+	state := &searchState{
+		tt:          limits.TT,
+		onInfo:      limits.OnInfo,
+		ctx:         ctx,
+		start:       time.Now(),
+		nodeLimit:   limits.Nodes,
+		searchMoves: limits.SearchMoves,
+		pondering:   limits.Ponder,
+		ponderHit:   limits.PonderHit,
+		nullMove:    !limits.DisableNullMove,
+		reductions:  !limits.DisableLateMoveReductions,
+	}
+	state.clockStart = state.start
+	state.lastInfo = state.start
+	if !limits.Infinite {
+		state.softLimit, state.hardLimit = allocateTime(limits, pos.GetActiveSide())
+	}
+	pos = pos.Copy()
+	var result Result
+	for depth := 1; depth <= maxDepth; depth++ {
+		if depth > 1 && !state.startIteration() {
+			break
+		}
+		state.canStop = depth > 1
+		state.selDepth = 0
+		engineMove := moves.Move(0)
+		params := SearchParams{
+			Depth:      depth,
+			Ply:        depth,
+			Pos:        &pos,
+			EngineMove: &engineMove,
+			TT:         limits.TT,
+			state:      state,
+		}
+		score := aspirationSearch(result, params)
+		if state.stopped {
+			break
+		}
+		result = Result{
+			BestMove: engineMove,
+			Score:    score,
+			Depth:    depth,
+			PV:       state.principalVariation(),
+			Nodes:    state.nodes,
+			QNodes:   state.qnodes,
+		}
+		state.reportIteration(result)
+		if engineMove == 0 {
+			// the root is mate or stalemate, deeper iterations can not change the score
+			break
+		}
+	}
+	return result
+}
+
+// aspirationSearch searches an iteration in a narrow window around the score of the previous
+// iteration, which cuts off more of the tree. A score outside of the window is reported as a
+// bound and searched again with the window widened on that side. Shallow iterations and mate
+// scores are searched with the full window.
+func aspirationSearch(previous Result, p SearchParams) int {
+	alpha, beta := -infinity, infinity
+	delta := aspirationWindow
+	if p.Depth >= aspirationMinDepth && previous.Score > -mateThreshold && previous.Score < mateThreshold {
+		alpha, beta = previous.Score-delta, previous.Score+delta
+	}
+	for {
+		score := AlphaBeta(alpha, beta, p.Depth, p)
+		if p.state.stopped {
+			return score
+		}
+		switch {
+		case score <= alpha && alpha > -infinity:
+			p.state.reportBound(p.Depth, score, UpperBound)
+			alpha = score - delta
+		case score >= beta && beta < infinity:
+			p.state.reportBound(p.Depth, score, LowerBound)
+			beta = score + delta
+		default:
+			return score
+		}
+		delta *= 2
+		if alpha < -infinity {
+			alpha = -infinity
+		}
+		if beta > infinity {
+			beta = infinity
+		}
+	}
+}
+
+// startIteration reports whether there is time left to start another iteration.
+// Each iteration usually takes longer than all of the previous ones together,
+// so no new iteration is started once half of the soft limit has passed.
+func (s *searchState) startIteration() bool {
+	if s.ctx.Err() != nil {
+		return false
+	}
+	if s.nodeLimit > 0 && s.nodes >= s.nodeLimit {
+		return false
+	}
+	if s.checkPonderHit() {
+		return true
+	}
+	return s.softLimit == 0 || time.Since(s.clockStart) < s.softLimit/2
+}
+
+// checkPonderHit reports whether the search is still pondering.
+// The clock starts once the ponder move has been played.
+func (s *searchState) checkPonderHit() bool {
+	if !s.pondering {
+		return false
+	}
+	select {
+	case <-s.ponderHit:
+		s.pondering = false
+		s.clockStart = time.Now()
+	default:
+	}
+	return s.pondering
+}
+
+// shouldStop reports whether the search has to be abandoned
+func (s *searchState) shouldStop() bool {
+	if s == nil {
+		return false
+	}
+	// checking the clock on every node is too expensive
+	tick := s.nodes&1023 == 0
+	if tick {
+		// progress is reported during the first iteration too, only stopping waits for it
+		s.reportProgress()
+	}
+	if !s.canStop {
+		return false
+	}
+	if s.stopped {
+		return true
+	}
+	if s.nodeLimit > 0 && s.nodes >= s.nodeLimit {
+		s.stopped = true
+	}
+	if tick {
+		if s.ctx.Err() != nil {
+			s.stopped = true
+		} else if !s.checkPonderHit() && s.hardLimit > 0 && time.Since(s.clockStart) >= s.hardLimit {
+			s.stopped = true
+		}
+	}
+	return s.stopped
+}
+
+// isSearchMove reports whether move may be searched at the root
+func (s *searchState) isSearchMove(move moves.Move) bool {
+	if s == nil || len(s.searchMoves) == 0 {
+		return true
+	}
+	for _, searchMove := range s.searchMoves {
+		if searchMove.SameSquares(move) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *searchState) visitNode(height int) {
+	if s == nil {
+		return
+	}
+	s.nodes++
+	s.pvLen[height] = height
+	if height > s.selDepth {
+		s.selDepth = height
+	}
+}
+
+func (s *searchState) visitQuiescenceNode(height int) {
+	if s == nil {
+		return
+	}
+	s.qnodes++
+	s.visitNode(height)
+}
+
+// updatePV records move as the best move at height followed by the best line found below it
+func (s *searchState) updatePV(height int, move moves.Move) {
+	if s == nil {
+		return
+	}
+	s.pv[height][height] = move
+	for i := height + 1; i < s.pvLen[height+1]; i++ {
+		s.pv[height][i] = s.pv[height+1][i]
+	}
+	s.pvLen[height] = s.pvLen[height+1]
+}
+
+func (s *searchState) principalVariation() []moves.Move {
+	pv := make([]moves.Move, s.pvLen[0])
+	copy(pv, s.pv[0][:s.pvLen[0]])
+	return pv
+}
@@ -0,0 +1,197 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tonyOreglia/glee/pkg/moves"
+	"github.com/tonyOreglia/glee/pkg/position"
+)
+
+func TestSearch(t *testing.T) {
+	fen := "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1"
+	pos, _ := position.NewPositionFen(fen)
+	result := Search(context.Background(), pos, Limits{Depth: 3, DisableNullMove: true, DisableLateMoveReductions: true})
+	assert.Equal(t, fen, pos.GetFenString(), "search must not change the position")
+	assert.Equal(t, 3, result.Depth)
+	assert.True(t, len(result.PV) >= 3, "checks are extended")
+	assert.Equal(t, result.BestMove, result.PV[0])
+	var move moves.Move
+	params := SearchParams{Depth: 3, Pos: &pos, EngineMove: &move, state: &searchState{}}
+	assert.Equal(t, AlphaBeta(-infinity, infinity, 3, params), result.Score)
+	assert.True(t, result.Nodes > 0)
+
+	// every move of the principal variation is legal in turn
+	for _, move := range result.PV {
+		assert.True(t, MakeValidMove(move, &pos), move.String())
+	}
+}
+
+func TestSearchScoreIsRelativeToSideToMove(t *testing.T) {
+	pos, _ := position.NewPositionFen("3qk3/8/8/8/8/8/8/4K3 b - - 0 1")
+	result := Search(context.Background(), pos, Limits{Depth: 2})
+	assert.True(t, result.Score > 500, "black is up a queen")
+
+	pos, _ = position.NewPositionFen("3qk3/8/8/8/8/8/8/4K3 w - - 0 1")
+	result = Search(context.Background(), pos, Limits{Depth: 2})
+	assert.True(t, result.Score < -500, "white is down a queen")
+}
+
+func TestSearchStopsWhenContextIsDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	pos := position.StartingPosition()
+	result := Search(ctx, pos, Limits{Depth: 5})
+	assert.Equal(t, 1, result.Depth, "first iteration always completes")
+	assert.Equal(t, 1, len(result.PV))
+}
+
+func TestSearchInfiniteRunsUntilStopped(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan Result)
+	go func() {
+		done <- Search(ctx, position.StartingPosition(), Limits{Infinite: true})
+	}()
+	select {
+	case <-done:
+		t.Fatal("infinite search returned before being stopped")
+	case <-time.After(200 * time.Millisecond):
+	}
+	cancel()
+	select {
+	case result := <-done:
+		assert.NotEqual(t, 0, len(result.PV))
+	case <-time.After(time.Second):
+		t.Fatal("search did not stop when cancelled")
+	}
+}
+
+func TestSearchPonderStartsClockOnPonderHit(t *testing.T) {
+	ponderHit := make(chan struct{})
+	done := make(chan Result)
+	limits := Limits{MoveTime: 100 * time.Millisecond, Ponder: true, PonderHit: ponderHit}
+	go func() {
+		done <- Search(context.Background(), position.StartingPosition(), limits)
+	}()
+	select {
+	case <-done:
+		t.Fatal("pondering search returned before ponderhit")
+	case <-time.After(300 * time.Millisecond):
+	}
+	close(ponderHit)
+	select {
+	case result := <-done:
+		assert.NotEqual(t, 0, len(result.PV))
+	case <-time.After(time.Second):
+		t.Fatal("search did not honor the clock after ponderhit")
+	}
+}
+
+func TestSearchScoresMateByDistance(t *testing.T) {
+	// Ra8 is mate, slower mates are available too
+	pos, _ := position.NewPositionFen("6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1")
+	result := Search(context.Background(), pos, Limits{Depth: 4})
+	assert.Equal(t, "a1a8", result.BestMove.String())
+	assert.Equal(t, MateScore-1, result.Score)
+
+	pos, _ = position.NewPositionFen("R5k1/5ppp/8/8/8/8/8/6K1 b - - 0 1")
+	result = Search(context.Background(), pos, Limits{Depth: 2})
+	assert.Equal(t, -MateScore, result.Score, "mated at the root")
+}
+
+func TestSearchStopsAtTerminalRoot(t *testing.T) {
+	start := time.Now()
+	pos, _ := position.NewPositionFen("7k/5Q2/6K1/8/8/8/8/8 b - - 0 1")
+	result := Search(context.Background(), pos, Limits{MoveTime: 200 * time.Millisecond})
+	assert.Equal(t, 1, result.Depth, "stalemate")
+	assert.Equal(t, 0, result.Score)
+
+	pos, _ = position.NewPositionFen("R5k1/5ppp/8/8/8/8/8/6K1 b - - 0 1")
+	result = Search(context.Background(), pos, Limits{Infinite: true})
+	assert.Equal(t, 1, result.Depth, "mated")
+	assert.Equal(t, -MateScore, result.Score)
+	assert.Equal(t, moves.Move(0), result.BestMove)
+	assert.True(t, time.Since(start) < 100*time.Millisecond, time.Since(start).String())
+}
+
+func TestSearchScoresStalemateAsDraw(t *testing.T) {
+	pos, _ := position.NewPositionFen("7k/5Q2/6K1/8/8/8/8/8 b - - 0 1")
+	result := Search(context.Background(), pos, Limits{Depth: 2})
+	assert.Equal(t, 0, result.Score)
+
+	// taking the rook stalemates, a queen up white avoids it
+	pos, _ = position.NewPositionFen("k7/2K5/1r6/8/8/8/8/1Q6 w - - 0 1")
+	result = Search(context.Background(), pos, Limits{Depth: 3})
+	assert.NotEqual(t, "b1b6", result.BestMove.String())
+	assert.True(t, result.Score > 0)
+}
+
+func TestSearchScoresDraws(t *testing.T) {
+	// black is a queen down and can repeat the position
+	pos, _ := position.NewPositionFen("7k/8/8/8/8/8/8/1Q5K w - - 0 1")
+	for _, move := range [][2]string{{"h1", "g1"}, {"h8", "g8"}, {"g1", "h1"}} {
+		pos.MakeMoveAlgebraic(move[0], move[1])
+	}
+	result := Search(context.Background(), pos, Limits{Depth: 1})
+	assert.Equal(t, "g8h8", result.BestMove.String())
+	assert.Equal(t, 0, result.Score)
+
+	// every move completes fifty moves without a capture or pawn move
+	pos, _ = position.NewPositionFen("7k/8/8/8/8/8/8/1Q5K w - - 99 80")
+	result = Search(context.Background(), pos, Limits{Depth: 2})
+	assert.Equal(t, 0, result.Score)
+
+	// taking the last pawn leaves a lone knight which can not mate
+	pos, _ = position.NewPositionFen("7k/8/8/8/8/8/6p1/6NK w - - 0 1")
+	result = Search(context.Background(), pos, Limits{Depth: 1, SearchMoves: []moves.Move{*moves.NewMove([]int{63, 54})}})
+	assert.Equal(t, 0, result.Score)
+}
+
+func TestAspirationSearch(t *testing.T) {
+	fen := "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1"
+	search := func(previous int) (int, moves.Move, []Info) {
+		pos, _ := position.NewPositionFen(fen)
+		var move moves.Move
+		var infos []Info
+		state := &searchState{start: time.Now(), lastInfo: time.Now(), onInfo: func(info Info) {
+			infos = append(infos, info)
+		}}
+		params := SearchParams{Depth: 4, Pos: &pos, EngineMove: &move, state: state}
+		return aspirationSearch(Result{Score: previous}, params), move, infos
+	}
+	expected, expectedMove, _ := search(MateScore)
+	assert.True(t, expected > -500 && expected < 500, "full window for mate scores")
+
+	score, move, infos := search(expected + 10)
+	assert.Equal(t, expected, score)
+	assert.Equal(t, expectedMove, move)
+	assert.Empty(t, infos, "the score is inside the window")
+
+	score, move, infos = search(expected + 500)
+	assert.Equal(t, expected, score)
+	assert.Equal(t, expectedMove, move)
+	if assert.NotEmpty(t, infos) {
+		assert.Equal(t, UpperBound, infos[0].Bound)
+		assert.Equal(t, expected+500-aspirationWindow, infos[0].Score)
+		assert.Equal(t, 4, infos[0].Depth)
+	}
+
+	score, move, infos = search(expected - 500)
+	assert.Equal(t, expected, score)
+	assert.Equal(t, expectedMove, move)
+	if assert.NotEmpty(t, infos) {
+		assert.Equal(t, LowerBound, infos[0].Bound)
+		assert.Equal(t, expected-500+aspirationWindow, infos[0].Score)
+	}
+}
+
+func TestSearchKeepsPrincipalVariationWithWarmTable(t *testing.T) {
+	pos, _ := position.NewPositionFen("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1")
+	tt := NewTranspositionTable(DefaultHashSizeMb)
+	first := Search(context.Background(), pos, Limits{Depth: 7, TT: tt})
+	second := Search(context.Background(), pos, Limits{Depth: 7, TT: tt})
+	assert.True(t, len(first.PV) >= 7, "%v", first.PV)
+	assert.Equal(t, len(first.PV), len(second.PV), "stored results do not cut the principal variation short")
+}
@@ -0,0 +1,71 @@
+package engine
+
+import (
+	"time"
+
+	"github.com/tonyOreglia/glee/pkg/position"
+)
+
+// moveOverhead is kept in reserve on every move to cover communication lag
+const moveOverhead = 30 * time.Millisecond
+
+// minimumThinkingTime is the least time spent on a move when on the clock
+const minimumThinkingTime = 10 * time.Millisecond
+
+// defaultMovesToGo is the number of moves the remaining time is spread over
+// when the time control does not specify it (sudden death)
+const defaultMovesToGo = 30
+
+// hasTimeLimit reports whether limits bound the search of side by its clock or the move time.
+// The clock of the other side does not limit the search.
+func (l Limits) hasTimeLimit(side int) bool {
+	if l.MoveTime > 0 {
+		return true
+	}
+	if side == position.Black {
+		return l.BlackTime > 0
+	}
+	return l.WhiteTime > 0
+}
+
+// allocateTime decides how long to think on the current move.
+// The soft limit is the target thinking time, no new iteration is started once half of it has passed.
+// The hard limit aborts an iteration in progress. Zero means there is no time limit.
+func allocateTime(limits Limits, side int) (soft time.Duration, hard time.Duration) {
+	if limits.MoveTime > 0 {
+		available := limits.MoveTime - moveOverhead
+		if available < minimumThinkingTime {
+			available = minimumThinkingTime
+		}
+		return available, available
+	}
+	remaining, increment := limits.WhiteTime, limits.WhiteIncrement
+	if side == position.Black {
+		remaining, increment = limits.BlackTime, limits.BlackIncrement
+	}
+	if remaining <= 0 {
+		return 0, 0
+	}
+	movesToGo := limits.MovesToGo
+	if movesToGo <= 0 || movesToGo > defaultMovesToGo {
+		movesToGo = defaultMovesToGo
+	}
+	available := remaining - moveOverhead
+	if available < minimumThinkingTime {
+		available = minimumThinkingTime
+	}
+	soft = available/time.Duration(movesToGo) + increment*3/4
+	hard = soft * 4
+	// never risk more than half of the clock unless this is the last move before the time control
+	maxHard := available / 2
+	if movesToGo == 1 {
+		maxHard = available
+	}
+	if hard > maxHard {
+		hard = maxHard
+	}
+	if soft > hard {
+		soft = hard
+	}
+	return soft, hard
+}
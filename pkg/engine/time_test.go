@@ -0,0 +1,106 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tonyOreglia/glee/pkg/moves"
+	"github.com/tonyOreglia/glee/pkg/position"
+)
+
+func TestAllocateTime(t *testing.T) {
+	tests := map[string]struct {
+		limits  Limits
+		side    int
+		minSoft time.Duration
+		maxHard time.Duration
+	}{
+		"no clock": {
+			limits:  Limits{Depth: 5},
+			side:    position.White,
+			minSoft: 0,
+			maxHard: 0,
+		},
+		"fixed move time": {
+			limits:  Limits{MoveTime: time.Second},
+			side:    position.White,
+			minSoft: time.Second - moveOverhead,
+			maxHard: time.Second - moveOverhead,
+		},
+		"sudden death uses a fraction of the clock": {
+			limits:  Limits{WhiteTime: 60 * time.Second, BlackTime: time.Second},
+			side:    position.White,
+			minSoft: time.Second,
+			maxHard: 30 * time.Second,
+		},
+		"black uses black's clock": {
+			limits:  Limits{WhiteTime: 60 * time.Second, BlackTime: 6 * time.Second},
+			side:    position.Black,
+			minSoft: 100 * time.Millisecond,
+			maxHard: 3 * time.Second,
+		},
+		"increment adds to the allocation": {
+			limits:  Limits{WhiteTime: 10 * time.Second, WhiteIncrement: 2 * time.Second},
+			side:    position.White,
+			minSoft: 1500 * time.Millisecond,
+			maxHard: 5 * time.Second,
+		},
+		"last move before time control may use most of the clock": {
+			limits:  Limits{BlackTime: 10 * time.Second, MovesToGo: 1},
+			side:    position.Black,
+			minSoft: 5 * time.Second,
+			maxHard: 10*time.Second - moveOverhead,
+		},
+		"almost flagging still moves": {
+			limits:  Limits{WhiteTime: 20 * time.Millisecond},
+			side:    position.White,
+			minSoft: 0,
+			maxHard: minimumThinkingTime,
+		},
+	}
+	for name, test := range tests {
+		soft, hard := allocateTime(test.limits, test.side)
+		assert.True(t, soft >= test.minSoft, "%s: soft %v", name, soft)
+		assert.True(t, hard <= test.maxHard, "%s: hard %v", name, hard)
+		assert.True(t, soft <= hard, "%s: soft %v hard %v", name, soft, hard)
+	}
+}
+
+func TestHasTimeLimit(t *testing.T) {
+	assert.True(t, Limits{MoveTime: time.Second}.hasTimeLimit(position.Black))
+	assert.True(t, Limits{WhiteTime: time.Minute}.hasTimeLimit(position.White))
+	assert.False(t, Limits{WhiteTime: time.Minute}.hasTimeLimit(position.Black), "only white's clock is running")
+	assert.True(t, Limits{BlackTime: time.Minute}.hasTimeLimit(position.Black))
+	assert.False(t, Limits{Depth: 3}.hasTimeLimit(position.White))
+}
+
+func TestSearchWithoutClockOfSideToMove(t *testing.T) {
+	pos, _ := position.NewPositionFen("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1")
+	result := Search(context.Background(), pos, Limits{WhiteTime: time.Minute})
+	assert.Equal(t, DefaultSearchDepth, result.Depth, "black has no clock, the default depth applies")
+}
+
+func TestSearchHonorsMoveTime(t *testing.T) {
+	pos, _ := position.NewPositionFen("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+	start := time.Now()
+	result := Search(context.Background(), pos, Limits{MoveTime: 200 * time.Millisecond})
+	assert.True(t, time.Since(start) < 400*time.Millisecond, time.Since(start).String())
+	assert.True(t, result.Depth >= 1)
+	assert.NotEqual(t, 0, len(result.PV))
+}
+
+func TestSearchHonorsNodeLimit(t *testing.T) {
+	pos := position.StartingPosition()
+	result := Search(context.Background(), pos, Limits{Nodes: 2000})
+	assert.True(t, result.Depth >= 1)
+	assert.True(t, result.Nodes <= 2000, "completed iterations stay within the node limit")
+}
+
+func TestSearchMoves(t *testing.T) {
+	pos := position.StartingPosition()
+	searchMoves := []moves.Move{*moves.NewMove([]int{48, 40}), *moves.NewMove([]int{55, 47})}
+	result := Search(context.Background(), pos, Limits{Depth: 2, SearchMoves: searchMoves})
+	assert.Contains(t, searchMoves, result.BestMove)
+}
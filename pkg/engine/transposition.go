@@ -0,0 +1,165 @@
+package engine
+
+import (
+	"unsafe"
+
+	"github.com/tonyOreglia/glee/pkg/moves"
+)
+
+// DefaultHashSizeMb is the transposition table size used when none is configured
+const DefaultHashSizeMb = 16
+
+// Bound describes how a stored score relates to the true score of a position
+type Bound uint8
+
+const (
+	// ExactBound means the stored score is the true score of the position
+	ExactBound Bound = iota + 1
+	// LowerBound means the search failed high, the true score is at least the stored score
+	LowerBound
+	// UpperBound means the search failed low, the true score is at most the stored score
+	UpperBound
+)
+
+// TTEntry is a single transposition table slot
+type TTEntry struct {
+	Key   uint64
+	Move  moves.Move
+	Score int
+	Depth int
+	Bound Bound
+}
+
+// TranspositionTable caches search results keyed by position hash.
+// Entries are replaced by depth: a deeper search result is never
+// overwritten by a shallower search of a different position.
+type TranspositionTable struct {
+	entries []TTEntry
+	mask    uint64
+}
+
+// NewTranspositionTable allocates a table using at most sizeMb megabytes
+func NewTranspositionTable(sizeMb int) *TranspositionTable {
+	if sizeMb < 1 {
+		sizeMb = 1
+	}
+	maxEntries := uint64(sizeMb) * 1024 * 1024 / uint64(unsafe.Sizeof(TTEntry{}))
+	size := uint64(1)
+	for size*2 <= maxEntries {
+		size *= 2
+	}
+	return &TranspositionTable{
+		entries: make([]TTEntry, size),
+		mask:    size - 1,
+	}
+}
+
+// Clear empties the table, e.g. between games
+func (t *TranspositionTable) Clear() {
+	if t == nil {
+		return
+	}
+	for i := range t.entries {
+		t.entries[i] = TTEntry{}
+	}
+}
+
+// Hashfull estimates how full the table is in permill by sampling the first thousand slots
+func (t *TranspositionTable) Hashfull() int {
+	if t == nil {
+		return -1
+	}
+	sample := len(t.entries)
+	if sample > 1000 {
+		sample = 1000
+	}
+	used := 0
+	for i := 0; i < sample; i++ {
+		if t.entries[i].Bound != 0 {
+			used++
+		}
+	}
+	return used * 1000 / sample
+}
+
+// Probe looks up the entry stored for hash
+func (t *TranspositionTable) Probe(hash uint64) (TTEntry, bool) {
+	if t == nil {
+		return TTEntry{}, false
+	}
+	entry := t.entries[hash&t.mask]
+	if entry.Bound == 0 || entry.Key != hash {
+		return TTEntry{}, false
+	}
+	return entry, true
+}
+
+// Store saves a search result unless the slot holds a deeper result for another position
+func (t *TranspositionTable) Store(hash uint64, depth int, score int, bound Bound, move moves.Move) {
+	if t == nil {
+		return
+	}
+	entry := &t.entries[hash&t.mask]
+	if entry.Bound != 0 && entry.Key != hash && entry.Depth > depth {
+		return
+	}
+	*entry = TTEntry{
+		Key:   hash,
+		Move:  move,
+		Score: score,
+		Depth: depth,
+		Bound: bound,
+	}
+}
+
+// cutoff returns a score if the stored entry is deep enough to end the search
+// of the node within the alpha beta window without searching it again
+func (t *TranspositionTable) cutoff(hash uint64, depth int, height int, alpha int, beta int) (int, bool) {
+	entry, found := t.Probe(hash)
+	if !found || entry.Depth < depth {
+		return 0, false
+	}
+	entry.Score = scoreFromTT(entry.Score, height)
+	switch entry.Bound {
+	case ExactBound:
+		if entry.Score <= alpha {
+			return alpha, true
+		}
+		if entry.Score >= beta {
+			return beta, true
+		}
+		return entry.Score, true
+	case LowerBound:
+		if entry.Score >= beta {
+			return beta, true
+		}
+	case UpperBound:
+		if entry.Score <= alpha {
+			return alpha, true
+		}
+	}
+	return 0, false
+}
+
+// scoreToTT converts a mate score from distance to the root into distance to the node at height,
+// the same position can be reached at a different height in another search
+func scoreToTT(score int, height int) int {
+	if score > mateThreshold {
+		return score + height
+	}
+	if score < -mateThreshold {
+		return score - height
+	}
+	return score
+}
+
+// scoreFromTT reverses scoreToTT for a node at height
+func scoreFromTT(score int, height int) int {
+	if score > mateThreshold {
+		return score - height
+	}
+	if score < -mateThreshold {
+		return score + height
+	}
+	return score
+}
@@ -0,0 +1,89 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tonyOreglia/glee/pkg/moves"
+	"github.com/tonyOreglia/glee/pkg/position"
+)
+
+func TestTranspositionTableStoreAndProbe(t *testing.T) {
+	tt := NewTranspositionTable(1)
+	mv := moves.NewMove([]int{52, 36})
+	tt.Store(12345, 3, 42, ExactBound, *mv)
+	entry, found := tt.Probe(12345)
+	assert.True(t, found)
+	assert.Equal(t, 42, entry.Score)
+	assert.Equal(t, 3, entry.Depth)
+	assert.Equal(t, ExactBound, entry.Bound)
+	assert.Equal(t, *mv, entry.Move)
+
+	_, found = tt.Probe(12346)
+	assert.False(t, found)
+
+	tt.Clear()
+	_, found = tt.Probe(12345)
+	assert.False(t, found)
+}
+
+func TestTranspositionTableReplaceByDepth(t *testing.T) {
+	tt := NewTranspositionTable(1)
+	size := uint64(len(tt.entries))
+	deep := uint64(7)
+	shallow := deep + size
+
+	tt.Store(deep, 5, 100, ExactBound, moves.Move(0))
+	tt.Store(shallow, 2, 200, ExactBound, moves.Move(0))
+	_, found := tt.Probe(shallow)
+	assert.False(t, found, "shallower result must not replace a deeper one")
+	entry, _ := tt.Probe(deep)
+	assert.Equal(t, 100, entry.Score)
+
+	tt.Store(shallow, 6, 300, LowerBound, moves.Move(0))
+	entry, found = tt.Probe(shallow)
+	assert.True(t, found, "deeper result replaces a shallower one")
+	assert.Equal(t, 300, entry.Score)
+
+	tt.Store(shallow, 1, 400, UpperBound, moves.Move(0))
+	entry, _ = tt.Probe(shallow)
+	assert.Equal(t, 400, entry.Score, "same position is always replaced")
+}
+
+func TestTranspositionTableCutoff(t *testing.T) {
+	tt := NewTranspositionTable(1)
+	tt.Store(1, 4, 50, LowerBound, moves.Move(0))
+	_, ok := tt.cutoff(1, 5, 0, -100, 40)
+	assert.False(t, ok, "entry is too shallow")
+	score, ok := tt.cutoff(1, 4, 0, -100, 40)
+	assert.True(t, ok)
+	assert.Equal(t, 40, score)
+	_, ok = tt.cutoff(1, 4, 0, -100, 60)
+	assert.False(t, ok, "lower bound below beta does not cut")
+}
+
+func TestAlphaBetaWithTranspositionTable(t *testing.T) {
+	fens := []string{
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"n1n5/PPPk4/8/8/8/8/4Kppp/5N1N b - - 0 1",
+	}
+	tt := NewTranspositionTable(DefaultHashSizeMb)
+	for _, fen := range fens {
+		withoutTT := alphaBetaScore(fen, 3, nil)
+		assert.Equal(t, withoutTT, alphaBetaScore(fen, 3, tt), fen)
+		// a second search re-uses the stored results
+		assert.Equal(t, withoutTT, alphaBetaScore(fen, 3, tt), fen)
+	}
+}
+
+func alphaBetaScore(fen string, depth int, tt *TranspositionTable) int {
+	pos, _ := position.NewPositionFen(fen)
+	params := SearchParams{
+		Depth:      depth,
+		Pos:        &pos,
+		EngineMove: new(moves.Move),
+		TT:         tt,
+	}
+	return AlphaBeta(-10000, 10000, depth, params)
+}
@@ -0,0 +1,72 @@
+package generate
+
+import (
+	"math/bits"
+
+	"github.com/tonyOreglia/glee/pkg/hashtables"
+	"github.com/tonyOreglia/glee/pkg/position"
+)
+
+// attackers holds the pieces of one side grouped by how they attack
+type attackers struct {
+	pawns         uint64
+	knights       uint64
+	bishopsQueens uint64
+	rooksQueens   uint64
+	king          uint64
+	// blackPawns is set when the pawns move towards index 63
+	blackPawns bool
+}
+
+// opponentAttackers returns the pieces of the side that is not to move
+func opponentAttackers(pos *position.Position) attackers {
+	opponent := pos.GetWhiteBitboards()
+	if pos.IsWhitesTurn() {
+		opponent = pos.GetBlackBitboards()
+	}
+	return attackers{
+		pawns:         opponent[position.Pawns].Value(),
+		knights:       opponent[position.Knights].Value(),
+		bishopsQueens: opponent[position.Bishops].Value() | opponent[position.Queen].Value(),
+		rooksQueens:   opponent[position.Rooks].Value() | opponent[position.Queen].Value(),
+		king:          opponent[position.King].Value(),
+		blackPawns:    pos.IsWhitesTurn(),
+	}
+}
+
+// attackersTo returns the pieces attacking sq on the occupied squares occSqsBb.
+// Unlike Position.AttackersTo the occupancy can differ from the position's.
+func (a attackers) attackersTo(sq int, occSqsBb uint64, ht *hashtables.HashTables) uint64 {
+	sqBb := uint64(1) << uint(sq)
+	return ht.KnightAttackBbHash[sq]&a.knights |
+		ht.LegalKingMovesNoCastlingBbHash[sq]&a.king |
+		ht.BishopAttacks(sq, occSqsBb)&a.bishopsQueens |
+		ht.RookAttacks(sq, occSqsBb)&a.rooksQueens |
+		pawnAttacksBb(sqBb, !a.blackPawns, ht)&a.pawns
+}
+
+// attackedSqs returns every square attacked by the pieces
+func (a attackers) attackedSqs(occSqsBb uint64, ht *hashtables.HashTables) uint64 {
+	attacked := pawnAttacksBb(a.pawns, a.blackPawns, ht)
+	for bb := a.knights; bb != 0; bb &= bb - 1 {
+		attacked |= ht.KnightAttackBbHash[bits.TrailingZeros64(bb)]
+	}
+	for bb := a.bishopsQueens; bb != 0; bb &= bb - 1 {
+		attacked |= ht.BishopAttacks(bits.TrailingZeros64(bb), occSqsBb)
+	}
+	for bb := a.rooksQueens; bb != 0; bb &= bb - 1 {
+		attacked |= ht.RookAttacks(bits.TrailingZeros64(bb), occSqsBb)
+	}
+	for bb := a.king; bb != 0; bb &= bb - 1 {
+		attacked |= ht.LegalKingMovesNoCastlingBbHash[bits.TrailingZeros64(bb)]
+	}
+	return attacked
+}
+
+// pawnAttacksBb returns the squares attacked by pawnsBb. White pawns move towards index 0.
+func pawnAttacksBb(pawnsBb uint64, blackPawns bool, ht *hashtables.HashTables) uint64 {
+	if blackPawns {
+		return (pawnsBb<<7)&^ht.HfileBb | (pawnsBb<<9)&^ht.AfileBb
+	}
+	return (pawnsBb>>7)&^ht.AfileBb | (pawnsBb>>9)&^ht.HfileBb
+}
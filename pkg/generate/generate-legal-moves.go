@@ -0,0 +1,107 @@
+package generate
+
+import (
+	"math/bits"
+
+	"github.com/tonyOreglia/glee/pkg/hashtables"
+	"github.com/tonyOreglia/glee/pkg/moves"
+	"github.com/tonyOreglia/glee/pkg/position"
+)
+
+// GenerateLegalMoves generates the moves that do not leave the king of the side to move in check.
+// The pinned pieces, the pieces giving check and the squares that stop the check are found up front,
+// so pseudo legal moves are filtered without making them.
+func GenerateLegalMoves(pos *position.Position) *moves.Moves {
+	ht := hashtables.Lookup
+	legalMoves := moves.NewMovesList()
+	kingBb := pos.ActiveSideKingBb()
+	king := kingBb.Lsb()
+	occSqsBb := pos.AllOccupiedSqsBb().Value()
+	opponent := opponentAttackers(pos)
+	checkersBb := pos.Checkers().Value()
+
+	// the king is left out of the occupied squares, it can't step back along the line of a checking slider
+	attackedBb := opponent.attackedSqs(occSqsBb&^kingBb.Value(), ht)
+	kingMoves := moves.NewMovesList()
+	GenerateKingMoves(pos, kingMoves, ht)
+	for _, move := range kingMoves.GetMovesList() {
+		if attackedBb&(uint64(1)<<uint(move.Destination())) != 0 {
+			continue
+		}
+		if move.IsCastle() {
+			slidingSq := ht.LookupCastlingSlidingSqByDest[uint64(move.Destination())]
+			if checkersBb != 0 || attackedBb&(uint64(1)<<slidingSq) != 0 {
+				continue
+			}
+		}
+		legalMoves.Add(move)
+	}
+	// in double check only the king can move
+	if bits.OnesCount64(checkersBb) > 1 {
+		return legalMoves
+	}
+	evasionBb := ^uint64(0)
+	if checkersBb != 0 {
+		evasionBb = checkersBb | ht.BetweenBb[king][bits.TrailingZeros64(checkersBb)]
+	}
+	pinnedBb, pinLines := pinnedPieces(pos, king, occSqsBb, opponent, ht)
+
+	pieceMoves := moves.NewMovesList()
+	GeneratePawnMoves(pos, pieceMoves, ht)
+	GenerateQueenMoves(pos, pieceMoves, ht)
+	GenerateRookMoves(pos, pieceMoves, ht)
+	GenerateKnightMoves(pos, pieceMoves, ht)
+	GenerateBishopMoves(pos, pieceMoves, ht)
+	for _, move := range pieceMoves.GetMovesList() {
+		origin, dest := move.Origin(), move.Destination()
+		if move.IsEnPassant() {
+			if enPassanteIsLegal(pos, move, king, occSqsBb, opponent, ht) {
+				legalMoves.Add(move)
+			}
+			continue
+		}
+		destBb := uint64(1) << uint(dest)
+		if destBb&evasionBb == 0 {
+			continue
+		}
+		if pinnedBb&(uint64(1)<<uint(origin)) != 0 && destBb&pinLines[origin] == 0 {
+			continue
+		}
+		legalMoves.Add(move)
+	}
+	return legalMoves
+}
+
+// pinnedPieces finds the pieces of the side to move that shield their king from a slider.
+// A pinned piece may only move along the line between the king and the pinning piece, or capture it.
+func pinnedPieces(pos *position.Position, king int, occSqsBb uint64, opponent attackers, ht *hashtables.HashTables) (uint64, [64]uint64) {
+	var pinnedBb uint64
+	var pinLines [64]uint64
+	ownSqsBb := pos.ActiveSideOccupiedSqsBb().Value()
+	// rays from the king pass through its own pieces to find the sliders behind them
+	opponentSqsBb := occSqsBb &^ ownSqsBb
+	snipersBb := ht.RookAttacks(king, opponentSqsBb)&opponent.rooksQueens |
+		ht.BishopAttacks(king, opponentSqsBb)&opponent.bishopsQueens
+	for ; snipersBb != 0; snipersBb &= snipersBb - 1 {
+		sniper := bits.TrailingZeros64(snipersBb)
+		blockersBb := ht.BetweenBb[king][sniper] & occSqsBb
+		if bits.OnesCount64(blockersBb) == 1 && blockersBb&ownSqsBb != 0 {
+			pinnedBb |= blockersBb
+			pinLines[bits.TrailingZeros64(blockersBb)] = ht.BetweenBb[king][sniper] | uint64(1)<<uint(sniper)
+		}
+	}
+	return pinnedBb, pinLines
+}
+
+// enPassanteIsLegal makes an en passante capture on the occupied squares and checks the king.
+// Two pawns leave the rank at once, which can uncover an attack no pin test finds.
+func enPassanteIsLegal(pos *position.Position, move moves.Move, king int, occSqsBb uint64, opponent attackers, ht *hashtables.HashTables) bool {
+	capturedSq := move.Destination() + 8
+	if pos.IsBlacksTurn() {
+		capturedSq = move.Destination() - 8
+	}
+	capturedBb := uint64(1) << uint(capturedSq)
+	occSqsBb = occSqsBb&^(uint64(1)<<uint(move.Origin()))&^capturedBb | uint64(1)<<uint(move.Destination())
+	opponent.pawns &^= capturedBb
+	return opponent.attackersTo(king, occSqsBb, ht) == 0
+}
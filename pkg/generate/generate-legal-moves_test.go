@@ -0,0 +1,105 @@
+package generate
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tonyOreglia/glee/pkg/moves"
+	"github.com/tonyOreglia/glee/pkg/position"
+)
+
+func TestGenerateLegalMoves(t *testing.T) {
+	tests := map[string]struct {
+		pos          string
+		destinations []string
+	}{
+		"pinned rook moves along the pin": {
+			"4r1k1/8/8/8/8/8/4R3/4K3 w - - 0 1",
+			[]string{"d1", "d2", "e3", "e4", "e5", "e6", "e7", "e8", "f1", "f2"},
+		},
+		"pinned knight can't move": {
+			"6k1/8/8/8/1b6/8/3N4/4K3 w - - 0 1",
+			[]string{"d1", "e2", "f1", "f2"},
+		},
+		"block or capture the checker": {
+			"r5k1/8/1N6/8/8/8/2B5/K7 w - - 0 1",
+			[]string{"a4", "a4", "a8", "b1", "b2"},
+		},
+		"double check moves the king": {
+			"4r1k1/8/8/8/8/5n2/7Q/4K3 w - - 0 1",
+			[]string{"d1", "f1", "f2"},
+		},
+		"king can't step back along the checking line": {
+			"4r1k1/8/8/8/4K3/8/8/8 w - - 0 1",
+			[]string{"d3", "d4", "d5", "f3", "f4", "f5"},
+		},
+		"en passante uncovers a rank attack": {
+			"8/8/8/K2pP2r/8/8/8/6k1 w - d6 0 2",
+			[]string{"a4", "a6", "b4", "b5", "b6", "e6"},
+		},
+		"en passante captures the checker": {
+			"8/8/8/2k5/3Pp3/8/8/4K3 b - d3 0 1",
+			[]string{"b4", "b5", "b6", "c4", "c6", "d3", "d4", "d5", "d6"},
+		},
+	}
+	for name, test := range tests {
+		pos, err := position.NewPositionFen(test.pos)
+		assert.Nil(t, err, name)
+		var destinations []string
+		for _, mv := range GenerateLegalMoves(pos).GetMovesList() {
+			destinations = append(destinations, algebraic(mv.Destination()))
+		}
+		sort.Strings(destinations)
+		assert.Equal(t, test.destinations, destinations, name)
+	}
+}
+
+func TestGenerateLegalCastling(t *testing.T) {
+	tests := map[string]struct {
+		pos       string
+		kingSide  bool
+		queenSide bool
+	}{
+		"both sides free":               {"4k3/8/8/8/8/8/8/R3K2R w KQ - 0 1", true, true},
+		"sliding square attacked":       {"4kr2/8/8/8/8/8/8/R3K2R w KQ - 0 1", false, true},
+		"destination attacked":          {"4k1r1/8/8/8/8/8/8/R3K2R w KQ - 0 1", false, true},
+		"in check":                      {"4r1k1/8/8/8/8/8/8/R3K2R w KQ - 0 1", false, false},
+		"only the rook's path attacked": {"1r2k3/8/8/8/8/8/8/R3K2R w KQ - 0 1", true, true},
+	}
+	for name, test := range tests {
+		pos, err := position.NewPositionFen(test.pos)
+		assert.Nil(t, err, name)
+		mvs := GenerateLegalMoves(pos)
+		_, found := mvs.FindMove(60, 62, 0)
+		assert.Equal(t, test.kingSide, found, name)
+		_, found = mvs.FindMove(60, 58, 0)
+		assert.Equal(t, test.queenSide, found, name)
+	}
+}
+
+func algebraic(sq int) string {
+	return string([]byte{byte('a' + sq%8), byte('8' - sq/8)})
+}
+
+func TestGeneratedMoveFlags(t *testing.T) {
+	// white can take the rook, capture en passante, double push, castle and promote with a capture
+	pos, _ := position.NewPositionFen("1r2k3/P7/8/3pP3/8/8/1P6/R3K3 w Q d6 0 2")
+	mvs := GenerateLegalMoves(pos)
+
+	mv, _ := mvs.FindMove(28, 19, 0)
+	assert.True(t, mv.Is(moves.Capture|moves.EnPassant), "exd6")
+	assert.Equal(t, position.Pawns, mv.CapturedPiece())
+	mv, _ = mvs.FindMove(49, 33, 0)
+	assert.True(t, mv.IsDoublePush(), "b4")
+	assert.False(t, mv.IsCapture())
+	mv, _ = mvs.FindMove(49, 41, 0)
+	assert.Equal(t, *moves.NewMove([]int{49, 41}), mv, "b3 has no flags")
+	mv, _ = mvs.FindMove(60, 58, 0)
+	assert.True(t, mv.IsCastle(), "O-O-O")
+	mv, _ = mvs.FindMove(8, 1, position.Knights)
+	assert.True(t, mv.IsCapture(), "axb8=N")
+	assert.Equal(t, position.Rooks, mv.CapturedPiece())
+	mv, _ = mvs.FindMove(8, 0, position.Queen)
+	assert.False(t, mv.IsCapture(), "a8=Q")
+}
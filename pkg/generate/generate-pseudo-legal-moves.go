@@ -39,7 +39,7 @@ func generateLegalMovesForSinglePiece(
 		validMovesBb := genValidMovesFn(piecePosition, pos.AllOccupiedSqsBb().Value(), ht)
 		// can't move to square occupied by your own pieces
 		validMovesBb.RemoveOverlappingBits(pos.ActiveSideOccupiedSqsBb())
-		addValidMovesToArray(movesList, piecePosition, validMovesBb)
+		addValidMovesToArray(pos, movesList, piecePosition, validMovesBb)
 	}
 }
 
@@ -111,7 +111,7 @@ func GenerateKingMoves(pos *position.Position, mvsList *moves.Moves, ht *hashtab
 			}
 		}
 	}
-	addValidMovesToArray(mvsList, kingPosition, validMovesBb)
+	addValidMovesToArray(pos, mvsList, kingPosition, validMovesBb)
 }
 
 func GeneratePotentialPawnAttacks(pos *position.Position, ht *hashtables.HashTables) *moves.Moves {
@@ -141,11 +141,11 @@ func GeneratePotentialPawnAttacks(pos *position.Position, ht *hashtables.HashTab
 
 	pawnAttackBb := getShiftedBb(&pawnPosBb, attackLeftShift).
 		RemoveOverlappingBits(hFileBb)
-	addPawnMovesToArray(mvsList, int(attackLeftShift), directionOfMovement, pawnAttackBb, promotionRank)
+	addPawnMovesToArray(pos, mvsList, int(attackLeftShift), directionOfMovement, pawnAttackBb, promotionRank)
 
 	pawnAttackBb = getShiftedBb(&pawnPosBb, attackRightShift).
 		RemoveOverlappingBits(aFileBb)
-	addPawnMovesToArray(mvsList, int(attackRightShift), directionOfMovement, pawnAttackBb, promotionRank)
+	addPawnMovesToArray(pos, mvsList, int(attackRightShift), directionOfMovement, pawnAttackBb, promotionRank)
 	return mvsList
 }
 
@@ -180,49 +180,71 @@ func GeneratePawnMoves(pos *position.Position, mvsList *moves.Moves, ht *hashtab
 	pawnAttackBb := getShiftedBb(&pawnPosBb, attackLeftShift).
 		RemoveOverlappingBits(hFileBb).
 		BitwiseAnd(bitboard.ReturnCombined(pos.InactiveSideOccupiedSqsBb(), enPassanteBB))
-	addPawnMovesToArray(mvsList, int(attackLeftShift), directionOfMovement, pawnAttackBb, promotionRank)
+	addPawnMovesToArray(pos, mvsList, int(attackLeftShift), directionOfMovement, pawnAttackBb, promotionRank)
 
 	pawnAttackBb = getShiftedBb(&pawnPosBb, attackRightShift).
 		RemoveOverlappingBits(aFileBb).
 		BitwiseAnd(bitboard.ReturnCombined(pos.InactiveSideOccupiedSqsBb(), enPassanteBB))
-	addPawnMovesToArray(mvsList, int(attackRightShift), directionOfMovement, pawnAttackBb, promotionRank)
+	addPawnMovesToArray(pos, mvsList, int(attackRightShift), directionOfMovement, pawnAttackBb, promotionRank)
 
 	pawnPushBb := getShiftedBb(&pawnPosBb, 8)
 	pawnPushBb.RemoveOverlappingBits(pos.AllOccupiedSqsBb())
 	doubleRankpawnPushBb := getShiftedBb(pawnPushBb, 8)
-	addPawnMovesToArray(mvsList, 8, directionOfMovement, pawnPushBb, promotionRank)
+	addPawnMovesToArray(pos, mvsList, 8, directionOfMovement, pawnPushBb, promotionRank)
 	doubleRankpawnPushBb.BitwiseAnd(doublePushMask)
 	doubleRankpawnPushBb.RemoveOverlappingBits(pos.AllOccupiedSqsBb())
-	addPawnMovesToArray(mvsList, 16, directionOfMovement, doubleRankpawnPushBb, promotionRank)
+	addPawnMovesToArray(pos, mvsList, 16, directionOfMovement, doubleRankpawnPushBb, promotionRank)
 }
 
-func addPawnMovesToArray(movesList *moves.Moves, shift int, shiftDirection int, pawnPushBb *bitboard.Bitboard, promoRank *bitboard.Bitboard) {
+func addPawnMovesToArray(pos *position.Position, movesList *moves.Moves, shift int, shiftDirection int, pawnPushBb *bitboard.Bitboard, promoRank *bitboard.Bitboard) {
 	shift = shift * shiftDirection
 	for pawnPushBb.Value() != 0 {
 		dest := pawnPushBb.Lsb()
 		pawnPushBb.RemoveBit(dest)
 		origin := dest + shift
+		captured, flags := capturedPiece(pos, dest)
+		switch {
+		case dest == pos.EnPassante():
+			captured, flags = position.Pawns, moves.Capture|moves.EnPassant
+		case shift == 16 || shift == -16:
+			flags = moves.DoublePush
+		}
 		destBb := new(bitboard.Bitboard)
 		destBb.SetBit(dest)
 		if destBb.BitwiseAnd(promoRank).Value() != uint64(0) {
-			movesList.AddPromotionMove(origin, dest, position.Queen)
-			movesList.AddPromotionMove(origin, dest, position.Rooks)
-			movesList.AddPromotionMove(origin, dest, position.Knights)
-			movesList.AddPromotionMove(origin, dest, position.Bishops)
+			movesList.Add(moves.New(origin, dest, position.Queen, captured, flags))
+			movesList.Add(moves.New(origin, dest, position.Rooks, captured, flags))
+			movesList.Add(moves.New(origin, dest, position.Knights, captured, flags))
+			movesList.Add(moves.New(origin, dest, position.Bishops, captured, flags))
 		} else {
-			movesList.AddMove(origin, dest)
+			movesList.Add(moves.New(origin, dest, 0, captured, flags))
 		}
 	}
 }
 
 // AddValidMovesToArray save subset of valid moves from current position
-func addValidMovesToArray(movesList *moves.Moves, index int, validMovesBb *bitboard.Bitboard) {
+func addValidMovesToArray(pos *position.Position, movesList *moves.Moves, index int, validMovesBb *bitboard.Bitboard) {
 	var validMove int
+	kingBb := pos.ActiveSideKingBb()
+	isKing := kingBb.BitIsSet(index)
 	for validMovesBb.Value() != 0 {
 		validMove = validMovesBb.Lsb()
 		validMovesBb.RemoveBit(validMove)
-		movesList.AddMove(index, validMove)
+		captured, flags := capturedPiece(pos, validMove)
+		if isKing && (validMove-index == 2 || validMove-index == -2) {
+			flags = moves.Castle
+		}
+		movesList.Add(moves.New(index, validMove, 0, captured, flags))
+	}
+}
+
+// capturedPiece returns the opposing piece on dest and the capture flag, or zero when dest is empty
+func capturedPiece(pos *position.Position, dest int) (int, moves.Flag) {
+	if pos.InactiveSideOccupiedSqsBb().BitIsNotSet(dest) {
+		return 0, 0
 	}
+	piece, _ := pos.PieceAt(dest)
+	return piece, moves.Capture
 }
 
 func getKnightMovesBb(index int, occSqsBb uint64, ht *hashtables.HashTables) *bitboard.Bitboard {
@@ -231,23 +253,15 @@ func getKnightMovesBb(index int, occSqsBb uint64, ht *hashtables.HashTables) *bi
 }
 
 func generateSlidingMovesBb(index int, occSqsBb uint64, ht *hashtables.HashTables) *bitboard.Bitboard {
-	slidingMvs := generateValidDiagonalSlidingMovesBb(index, occSqsBb, ht)
-	slidingMvs.Combine(generateValidStraightSlidingMovesBb(index, occSqsBb, ht))
-	return slidingMvs
+	return bitboard.NewBitboard(ht.QueenAttacks(index, occSqsBb))
 }
 
 func generateValidDiagonalSlidingMovesBb(index int, occSqsBb uint64, ht *hashtables.HashTables) *bitboard.Bitboard {
-	return generateValidDirectionalMovesBb(index, ht.NorthEastArrayBbHash, occSqsBb, getMsb).Combine(
-		generateValidDirectionalMovesBb(index, ht.NorthWestArrayBbHash, occSqsBb, getMsb).Combine(
-			generateValidDirectionalMovesBb(index, ht.SouthEastArrayBbHash, occSqsBb, getLsb).Combine(
-				generateValidDirectionalMovesBb(index, ht.SouthWestArrayBbHash, occSqsBb, getLsb))))
+	return bitboard.NewBitboard(ht.BishopAttacks(index, occSqsBb))
 }
 
 func generateValidStraightSlidingMovesBb(index int, occSqsBb uint64, ht *hashtables.HashTables) *bitboard.Bitboard {
-	return generateValidDirectionalMovesBb(index, ht.NorthArrayBbHash, occSqsBb, getMsb).Combine(
-		generateValidDirectionalMovesBb(index, ht.SouthArrayBbHash, occSqsBb, getLsb).Combine(
-			generateValidDirectionalMovesBb(index, ht.EastArrayBbHash, occSqsBb, getLsb).Combine(
-				generateValidDirectionalMovesBb(index, ht.WestArrayBbHash, occSqsBb, getMsb))))
+	return bitboard.NewBitboard(ht.RookAttacks(index, occSqsBb))
 }
 
 func generateValidDirectionalMovesBb(
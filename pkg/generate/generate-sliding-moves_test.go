@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/tonyOreglia/glee/pkg/bitboard"
 	"github.com/tonyOreglia/glee/pkg/hashtables"
+	"github.com/tonyOreglia/glee/pkg/position"
 )
 
 func TestGenerateValidDirectionalMovesBb(t *testing.T) {
@@ -40,3 +41,65 @@ func TestGenerateValidDiagonalSlidingMovesBb(t *testing.T) {
 	expectedValidMvsBb = bitboard.NewBitboard(uint64(0x402000204000000))
 	assert.Equal(t, expectedValidMvsBb.Value(), validMvsBb.Value())
 }
+
+// slidingOccupancies are the occupied squares of the perft test positions
+func slidingOccupancies() []uint64 {
+	fens := []string{
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+		"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+	}
+	var occupancies []uint64
+	for _, fen := range fens {
+		pos, _ := position.NewPositionFen(fen)
+		occupancies = append(occupancies, pos.AllOccupiedSqsBb().Value())
+	}
+	return occupancies
+}
+
+// raySlidingMovesBb walks the rays of every direction up to the first blocker,
+// this is how sliding moves were generated before the magic bitboard tables
+func raySlidingMovesBb(index int, occSqsBb uint64, ht *hashtables.HashTables) *bitboard.Bitboard {
+	return generateValidDirectionalMovesBb(index, ht.NorthEastArrayBbHash, occSqsBb, getMsb).Combine(
+		generateValidDirectionalMovesBb(index, ht.NorthWestArrayBbHash, occSqsBb, getMsb).Combine(
+			generateValidDirectionalMovesBb(index, ht.SouthEastArrayBbHash, occSqsBb, getLsb).Combine(
+				generateValidDirectionalMovesBb(index, ht.SouthWestArrayBbHash, occSqsBb, getLsb).Combine(
+					generateValidDirectionalMovesBb(index, ht.NorthArrayBbHash, occSqsBb, getMsb).Combine(
+						generateValidDirectionalMovesBb(index, ht.SouthArrayBbHash, occSqsBb, getLsb).Combine(
+							generateValidDirectionalMovesBb(index, ht.EastArrayBbHash, occSqsBb, getLsb).Combine(
+								generateValidDirectionalMovesBb(index, ht.WestArrayBbHash, occSqsBb, getMsb))))))))
+}
+
+func TestSlidingMovesMatchRayWalk(t *testing.T) {
+	for _, occSqsBb := range slidingOccupancies() {
+		for index := 0; index < 64; index++ {
+			assert.Equal(t, raySlidingMovesBb(index, occSqsBb, hashtables.Lookup).Value(),
+				generateSlidingMovesBb(index, occSqsBb, hashtables.Lookup).Value(), index)
+		}
+	}
+}
+
+// BenchmarkSlidingMoves compares the magic bitboard lookup with the ray walk it replaced
+// on every square of the perft test positions
+func BenchmarkSlidingMoves(b *testing.B) {
+	occupancies := slidingOccupancies()
+	implementations := []struct {
+		name    string
+		sliding func(int, uint64, *hashtables.HashTables) *bitboard.Bitboard
+	}{
+		{"rays", raySlidingMovesBb},
+		{"magic", generateSlidingMovesBb},
+	}
+	for _, implementation := range implementations {
+		b.Run(implementation.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for _, occSqsBb := range occupancies {
+					for index := 0; index < 64; index++ {
+						implementation.sliding(index, occSqsBb, hashtables.Lookup)
+					}
+				}
+			}
+		})
+	}
+}
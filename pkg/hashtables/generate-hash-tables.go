@@ -40,11 +40,16 @@ type HashTables struct {
 	LegalKingMovesNoCastlingBbHash        [64]uint64
 	CastlingBits                          [2]uint64
 	LegalPawnMovesBbHash                  [2][64]uint64
+	PawnAttacksBbHash                     [2][64]uint64
 	WhiteKingSideCastlingBitsMustBeClear  uint64
 	BlacklKingSideCastlingBitsMustBeClear uint64
 	WhiteQueenSideCastlingBitsMustBeClear uint64
 	BlackQueenSideCastlingBitsMustBeClear uint64
 	LookupCastlingSlidingSqByDest         map[uint64]uint64
+	RookMagics                            [64]Magic
+	BishopMagics                          [64]Magic
+	// BetweenBb holds the squares strictly between two squares on a common line, zero otherwise
+	BetweenBb [64][64]uint64
 }
 
 func CalculateAllLookupBbs() *HashTables {
@@ -89,6 +94,9 @@ func CalculateAllLookupBbs() *HashTables {
 	generateSingleBitLookup(hashTables)
 	generateArrayBitboardLookup(hashTables)
 	generateEnPassantBitboardLookup(hashTables)
+	generatePawnAttackLookup(hashTables)
+	generateMagicLookup(hashTables)
+	generateBetweenLookup(hashTables)
 
 	hashTables.CastlingBits[0] = 0
 	hashTables.CastlingBits[0] |= hashTables.SingleIndexBbHash[62] | hashTables.SingleIndexBbHash[58]
@@ -124,6 +132,15 @@ func generateEnPassantBitboardLookup(ht *HashTables) {
 	}
 }
 
+// generatePawnAttackLookup finds the squares a pawn of each side attacks diagonally
+func generatePawnAttackLookup(ht *HashTables) {
+	for i := 0; i < 64; i++ {
+		sqBb := ht.SingleIndexBbHash[i]
+		ht.PawnAttacksBbHash[0][i] = (sqBb>>7)&^ht.AfileBb | (sqBb>>9)&^ht.HfileBb
+		ht.PawnAttacksBbHash[1][i] = (sqBb<<7)&^ht.HfileBb | (sqBb<<9)&^ht.AfileBb
+	}
+}
+
 func generateArrayBitboardLookup(ht *HashTables) {
 	for index := 0; index < 64; index++ {
 		northOfIndex := index
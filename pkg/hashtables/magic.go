@@ -0,0 +1,184 @@
+package hashtables
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// Magic finds the attacks of a sliding piece on a single square.
+// The occupied squares that can block the piece are selected with Mask, multiplied
+// by the magic number and shifted to give an index into the square's attack table.
+type Magic struct {
+	Mask    uint64
+	Magic   uint64
+	Shift   uint
+	Attacks []uint64
+}
+
+// index returns the position of the attacks for occupied squares occSqsBb in the attack table
+func (m *Magic) index(occSqsBb uint64) uint64 {
+	return ((occSqsBb & m.Mask) * m.Magic) >> m.Shift
+}
+
+// direction is a single step of a sliding piece as a change of file and rank.
+// Ranks are counted from the top of the board, so rank 0 is the eighth rank.
+type direction struct {
+	file int
+	rank int
+}
+
+var rookDirections = []direction{{0, -1}, {0, 1}, {1, 0}, {-1, 0}}
+var bishopDirections = []direction{{1, -1}, {-1, -1}, {1, 1}, {-1, 1}}
+
+// RookAttacks returns the squares attacked by a rook on sq given the occupied squares
+func (ht *HashTables) RookAttacks(sq int, occSqsBb uint64) uint64 {
+	m := &ht.RookMagics[sq]
+	return m.Attacks[m.index(occSqsBb)]
+}
+
+// BishopAttacks returns the squares attacked by a bishop on sq given the occupied squares
+func (ht *HashTables) BishopAttacks(sq int, occSqsBb uint64) uint64 {
+	m := &ht.BishopMagics[sq]
+	return m.Attacks[m.index(occSqsBb)]
+}
+
+// QueenAttacks returns the squares attacked by a queen on sq given the occupied squares
+func (ht *HashTables) QueenAttacks(sq int, occSqsBb uint64) uint64 {
+	return ht.RookAttacks(sq, occSqsBb) | ht.BishopAttacks(sq, occSqsBb)
+}
+
+// rookMagicNumbers and bishopMagicNumbers were found by trying random sparse numbers,
+// see findMagic in the tests. Searching for them on start up takes too long.
+var rookMagicNumbers = [64]uint64{
+	0x008004114002A080, 0x2A40004010082001, 0xC180200010008008, 0x15000500089000A0,
+	0x1080080080020400, 0x2200110600040870, 0x1080610012000080, 0x020001040088402A,
+	0x0001002040800100, 0x3008802000804000, 0x2102002080401200, 0x0010800800100082,
+	0x0151000800100500, 0x3006001008420104, 0x8000808002000100, 0x0001002080410002,
+	0x1000928000224002, 0x2010044000402008, 0x2040220010420080, 0x8001818050000800,
+	0x0804808008000400, 0x0021080120100440, 0x1010040010028108, 0x0200AA0000508104,
+	0x5480004040002000, 0x1800200140005001, 0x0200410300200010, 0x2800100100200904,
+	0x1024000480800800, 0x2203000300080400, 0x4500010400100208, 0x000000A200004104,
+	0x0880400020800080, 0x0010401000402004, 0x0101002003004014, 0x8200082202001040,
+	0x1041000413000800, 0x0002000400800280, 0x2010020001010004, 0x1000008042002104,
+	0x008000200242400E, 0x1110084020054004, 0x2810110120030040, 0x0010204012020008,
+	0x000A080011010004, 0x1402000804020010, 0x001C020001008080, 0x0001004100820004,
+	0x8A00400020801280, 0x0040002000408080, 0x00A0801000200080, 0x8000080080100080,
+	0x5420040008008080, 0x0000020080040080, 0x006052010810A400, 0x1000040450850200,
+	0x0842A04080010215, 0x0011004422001286, 0x1038110020014009, 0x0800210005100089,
+	0x1042001008042002, 0x0001000400020801, 0x3880011012081084, 0x0408010028408402,
+}
+
+var bishopMagicNumbers = [64]uint64{
+	0x8004680640440102, 0x1048104082244224, 0x00100080A1000240, 0x000404208100C124,
+	0x0081104002026880, 0x00890108C2008004, 0x0002291008040020, 0x4009010802010518,
+	0x0020102002840042, 0x8440530222040300, 0x00840C4C04005024, 0x44200404008C8211,
+	0x0AA0040420002400, 0x41038488A0080611, 0x02300280C4602008, 0x10A10422111008AA,
+	0x0008104082088200, 0x0020100204014214, 0x0210400808802200, 0x1022000422020006,
+	0x0050800400A04005, 0x0004C00201500442, 0x6018418088080904, 0x1000400022080404,
+	0xE820200004042400, 0x41500400289808A4, 0x0228080081020028, 0x1400808008020002,
+	0x0000840002020201, 0x0000820041004208, 0x2000808011080801, 0x380045005094011A,
+	0x00D0042070840841, 0x0100842004100260, 0xB004004808010201, 0x8040202020480080,
+	0x0020404040140100, 0x08100062004CC100, 0x8221480100220D00, 0x5048008122818A01,
+	0x90820550400C1A00, 0x00004A1005001008, 0x804022020200C042, 0x010400A018000103,
+	0x0000242094000202, 0x0010101008402022, 0x0010010821000499, 0x0804409401000041,
+	0x0201010802428011, 0x8400320110080800, 0x4C00089406488000, 0x0480000020880804,
+	0x000080C010411021, 0x1600101001084040, 0x0084084841040880, 0x00100A020C302020,
+	0x51B2002084042040, 0x10204A0C440404E0, 0x8440000108881105, 0x0008000030420200,
+	0x0400000842028200, 0x0022088488900100, 0x8400403092020040, 0x0009420800440080,
+}
+
+func generateMagicLookup(ht *HashTables) {
+	for sq := 0; sq < 64; sq++ {
+		ht.RookMagics[sq] = newMagic(sq, rookDirections, rookMagicNumbers[sq])
+		ht.BishopMagics[sq] = newMagic(sq, bishopDirections, bishopMagicNumbers[sq])
+	}
+}
+
+// generateBetweenLookup finds the squares between every pair of squares on a common rank, file or diagonal
+func generateBetweenLookup(ht *HashTables) {
+	for from := 0; from < 64; from++ {
+		for to := 0; to < 64; to++ {
+			fromBb, toBb := uint64(1)<<uint(from), uint64(1)<<uint(to)
+			switch {
+			case from == to:
+			case ht.RookAttacks(from, 0)&toBb != 0:
+				ht.BetweenBb[from][to] = ht.RookAttacks(from, toBb) & ht.RookAttacks(to, fromBb)
+			case ht.BishopAttacks(from, 0)&toBb != 0:
+				ht.BetweenBb[from][to] = ht.BishopAttacks(from, toBb) & ht.BishopAttacks(to, fromBb)
+			}
+		}
+	}
+}
+
+// newMagic fills the attack table of a slider on sq for every blocker configuration
+func newMagic(sq int, directions []direction, magic uint64) Magic {
+	m, ok := tryMagic(sq, directions, magic)
+	if !ok {
+		panic(fmt.Sprintf("magic number %#x does not work for square %d", magic, sq))
+	}
+	return m
+}
+
+// tryMagic builds the attack table for sq using magic, ok is false when
+// two blocker configurations with different attacks share a slot
+func tryMagic(sq int, directions []direction, magic uint64) (Magic, bool) {
+	mask := relevantOccupancyMask(sq, directions)
+	bitCount := bits.OnesCount64(mask)
+	m := Magic{
+		Mask:    mask,
+		Magic:   magic,
+		Shift:   uint(64 - bitCount),
+		Attacks: make([]uint64, 1<<uint(bitCount)),
+	}
+	used := make([]bool, len(m.Attacks))
+	// enumerate every subset of the mask
+	occupancy := uint64(0)
+	for {
+		attacks := slidingAttacks(sq, occupancy, directions)
+		index := m.index(occupancy)
+		if used[index] && m.Attacks[index] != attacks {
+			return m, false
+		}
+		used[index] = true
+		m.Attacks[index] = attacks
+		occupancy = (occupancy - mask) & mask
+		if occupancy == 0 {
+			return m, true
+		}
+	}
+}
+
+// relevantOccupancyMask returns the squares whose occupancy can block a slider on sq.
+// The last square of each ray is left out because it is attacked whether or not it is occupied.
+func relevantOccupancyMask(sq int, directions []direction) uint64 {
+	var mask uint64
+	for _, d := range directions {
+		file, rank := sq%8+d.file, sq/8+d.rank
+		for onBoard(file+d.file, rank+d.rank) {
+			mask |= uint64(1) << uint(rank*8+file)
+			file, rank = file+d.file, rank+d.rank
+		}
+	}
+	return mask
+}
+
+// slidingAttacks walks every ray from sq until it leaves the board or hits an occupied square
+func slidingAttacks(sq int, occSqsBb uint64, directions []direction) uint64 {
+	var attacks uint64
+	for _, d := range directions {
+		file, rank := sq%8+d.file, sq/8+d.rank
+		for onBoard(file, rank) {
+			bit := uint64(1) << uint(rank*8+file)
+			attacks |= bit
+			if occSqsBb&bit != 0 {
+				break
+			}
+			file, rank = file+d.file, rank+d.rank
+		}
+	}
+	return attacks
+}
+
+func onBoard(file int, rank int) bool {
+	return file >= 0 && file < 8 && rank >= 0 && rank < 8
+}
@@ -0,0 +1,96 @@
+package hashtables
+
+import (
+	"math/bits"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindMagic(t *testing.T) {
+	rand := newMagicRand(728361)
+	for _, sq := range []int{27, 36} {
+		magic := findMagic(sq, rookDirections, rand)
+		_, ok := tryMagic(sq, rookDirections, magic)
+		assert.True(t, ok)
+		magic = findMagic(sq, bishopDirections, rand)
+		_, ok = tryMagic(sq, bishopDirections, magic)
+		assert.True(t, ok)
+	}
+}
+
+func TestMagicAttacksMatchRayWalk(t *testing.T) {
+	rand := newMagicRand(1)
+	for sq := 0; sq < 64; sq++ {
+		for i := 0; i < 200; i++ {
+			occSqsBb := rand.sparse()
+			assert.Equal(t, slidingAttacks(sq, occSqsBb, rookDirections), Lookup.RookAttacks(sq, occSqsBb))
+			assert.Equal(t, slidingAttacks(sq, occSqsBb, bishopDirections), Lookup.BishopAttacks(sq, occSqsBb))
+		}
+	}
+}
+
+func TestMagicAttacks(t *testing.T) {
+	// rook on a8 with a blocker on a5 and the board otherwise empty
+	assert.Equal(t, uint64(0xFE)|uint64(1)<<8|uint64(1)<<16|uint64(1)<<24, Lookup.RookAttacks(0, uint64(1)<<24))
+	// bishop on e4 is blocked on c2 and g6
+	occSqsBb := uint64(1)<<50 | uint64(1)<<22
+	expected := uint64(1)<<43 | uint64(1)<<50 | uint64(1)<<29 | uint64(1)<<22 |
+		uint64(1)<<27 | uint64(1)<<18 | uint64(1)<<9 | uint64(1)<<0 |
+		uint64(1)<<45 | uint64(1)<<54 | uint64(1)<<63
+	assert.Equal(t, expected, Lookup.BishopAttacks(36, occSqsBb))
+	assert.Equal(t, Lookup.RookAttacks(36, occSqsBb)|expected, Lookup.QueenAttacks(36, occSqsBb))
+}
+
+func TestBetween(t *testing.T) {
+	// a8 to a5, b8 to d8, a8 to d5
+	assert.Equal(t, uint64(1)<<8|uint64(1)<<16, Lookup.BetweenBb[0][24])
+	assert.Equal(t, uint64(1)<<2, Lookup.BetweenBb[1][3])
+	assert.Equal(t, uint64(1)<<9|uint64(1)<<18, Lookup.BetweenBb[27][0])
+	assert.Equal(t, uint64(0), Lookup.BetweenBb[0][1], "adjacent squares")
+	assert.Equal(t, uint64(0), Lookup.BetweenBb[0][17], "knight jump")
+}
+
+func TestRelevantOccupancyMask(t *testing.T) {
+	// a rook in the corner can be blocked on six squares along each edge
+	assert.Equal(t, 12, bits.OnesCount64(relevantOccupancyMask(0, rookDirections)))
+	assert.Equal(t, 10, bits.OnesCount64(relevantOccupancyMask(27, rookDirections)))
+	assert.Equal(t, 9, bits.OnesCount64(relevantOccupancyMask(27, bishopDirections)))
+	assert.Equal(t, 6, bits.OnesCount64(relevantOccupancyMask(0, bishopDirections)))
+}
+
+// findMagic searches for a magic number for a slider on sq, it was used to find the magic numbers in use
+func findMagic(sq int, directions []direction, rand *magicRand) uint64 {
+	mask := relevantOccupancyMask(sq, directions)
+	for {
+		magic := rand.sparse()
+		// few bits in the top of the product rarely make a usable index
+		if bits.OnesCount64((mask*magic)>>56) < 6 {
+			continue
+		}
+		if _, ok := tryMagic(sq, directions, magic); ok {
+			return magic
+		}
+	}
+}
+
+// magicRand is a xorshift64* generator for magic number candidates
+type magicRand struct {
+	state uint64
+}
+
+func newMagicRand(seed uint64) *magicRand {
+	return &magicRand{state: seed}
+}
+
+func (r *magicRand) next() uint64 {
+	r.state ^= r.state >> 12
+	r.state ^= r.state << 25
+	r.state ^= r.state >> 27
+	return r.state * 0x2545F4914F6CDD1D
+}
+
+// sparse returns a random number with few bits set, these make good magic numbers
+func (r *magicRand) sparse() uint64 {
+	return r.next() & r.next() & r.next()
+}
@@ -5,55 +5,121 @@ import (
 	"strconv"
 )
 
-type Move struct {
-	origin      int
-	destination int
-	// Queen = 2 Bishops = 3 Knights = 4 Rooks = 5
-	promotion int
+// Move packs a chess move into a single integer.
+//
+//	bits  0-5  origin square
+//	bits  6-11 destination square
+//	bits 12-14 promotion piece, Queen = 2 Bishops = 3 Knights = 4 Rooks = 5
+//	bits 15-17 captured piece
+//	bits 18-21 flags
+type Move uint32
+
+// Flag marks a move as a capture, en passant capture, castle or double pawn push
+type Flag uint32
+
+const (
+	Capture    Flag = 1 << 18
+	EnPassant  Flag = 1 << 19
+	Castle     Flag = 1 << 20
+	DoublePush Flag = 1 << 21
+)
+
+const (
+	destinationShift = 6
+	promotionShift   = 12
+	capturedShift    = 15
+	sqMask           = 0x3F
+	pieceMask        = 0x7
+)
+
+// New returns a move from origin to dest. The captured piece is only stored
+// for captures, set the Capture flag along with it.
+func New(origin int, dest int, promotion int, captured int, flags Flag) Move {
+	return Move(uint32(origin)|
+		uint32(dest)<<destinationShift|
+		uint32(promotion)<<promotionShift|
+		uint32(captured)<<capturedShift) | Move(flags)
 }
 
 func NewMove(singleMove []int) *Move {
-	mv := &Move{origin: singleMove[0], destination: singleMove[1], promotion: 0}
-	return mv
+	mv := New(singleMove[0], singleMove[1], 0, 0, 0)
+	return &mv
 }
 
 func NewPromoMove(singleMove []int) *Move {
-	mv := &Move{origin: singleMove[0], destination: singleMove[1], promotion: singleMove[2]}
-	return mv
+	mv := New(singleMove[0], singleMove[1], singleMove[2], 0, 0)
+	return &mv
 }
 
-func (m *Move) CopyMove() *Move {
-	x := []int{m.origin, m.destination, m.promotion}
-	return NewPromoMove(x)
+func (m Move) CopyMove() *Move {
+	return &m
 }
 
-func (m *Move) Origin() int {
-	return m.origin
+func (m Move) Origin() int {
+	return int(m & sqMask)
 }
 
-func (m *Move) Destination() int {
-	return m.destination
+func (m Move) Destination() int {
+	return int(m>>destinationShift) & sqMask
 }
 
-func (m *Move) GetMoveSlice() []int {
-	return []int{m.origin, m.destination}
+func (m Move) GetMoveSlice() []int {
+	return []int{m.Origin(), m.Destination()}
 }
 
-func (m *Move) PromotionPiece() int {
-	return m.promotion
+func (m Move) PromotionPiece() int {
+	return int(m>>promotionShift) & pieceMask
 }
 
-func (m *Move) Print() {
-	fmt.Println(ConvertIndexToAlgebraic(m.origin) + ConvertIndexToAlgebraic(m.destination))
+// CapturedPiece returns the piece taken by the move, zero when nothing is captured
+func (m Move) CapturedPiece() int {
+	return int(m>>capturedShift) & pieceMask
 }
 
-func (m *Move) String() string {
-	mvString := ConvertIndexToAlgebraic(m.origin) + ConvertIndexToAlgebraic(m.destination)
-	if (m.promotion > 0) && (m.destination < 8) {
-		mvString += "Q"
-	}
-	if (m.promotion > 0) && (m.destination > 55) {
+// Is reports whether every flag in f is set on the move
+func (m Move) Is(f Flag) bool {
+	return Flag(m)&f == f
+}
+
+func (m Move) IsCapture() bool {
+	return m.Is(Capture)
+}
+
+func (m Move) IsEnPassant() bool {
+	return m.Is(EnPassant)
+}
+
+func (m Move) IsCastle() bool {
+	return m.Is(Castle)
+}
+
+func (m Move) IsDoublePush() bool {
+	return m.Is(DoublePush)
+}
+
+// SameSquares reports whether m and other move between the same squares with the same promotion,
+// ignoring the flags. Moves parsed from text carry no flags.
+func (m Move) SameSquares(other Move) bool {
+	const squaresAndPromotion = 1<<capturedShift - 1
+	return m&squaresAndPromotion == other&squaresAndPromotion
+}
+
+func (m Move) Print() {
+	fmt.Println(ConvertIndexToAlgebraic(m.Origin()) + ConvertIndexToAlgebraic(m.Destination()))
+}
+
+func (m Move) String() string {
+	mvString := ConvertIndexToAlgebraic(m.Origin()) + ConvertIndexToAlgebraic(m.Destination())
+	// UCI writes the promotion piece in lower case for either side
+	switch m.PromotionPiece() {
+	case 2:
 		mvString += "q"
+	case 3:
+		mvString += "b"
+	case 4:
+		mvString += "n"
+	case 5:
+		mvString += "r"
 	}
 	return mvString
 }
@@ -30,3 +30,40 @@ func TestConvertAlgebriacToIndex(t *testing.T) {
 	index, _ = ConvertAlgebriacToIndex("h8")
 	assert.Equal(t, 7, index)
 }
+
+func TestMovePacking(t *testing.T) {
+	mv := New(63, 0, 5, 4, Capture)
+	assert.Equal(t, 63, mv.Origin())
+	assert.Equal(t, 0, mv.Destination())
+	assert.Equal(t, 5, mv.PromotionPiece())
+	assert.Equal(t, 4, mv.CapturedPiece())
+	assert.True(t, mv.IsCapture())
+	assert.False(t, mv.IsEnPassant())
+	assert.False(t, mv.IsCastle())
+	assert.False(t, mv.IsDoublePush())
+
+	mv = New(28, 19, 0, 6, Capture|EnPassant)
+	assert.True(t, mv.Is(Capture|EnPassant))
+	assert.False(t, mv.Is(Capture|Castle))
+	assert.Equal(t, 0, mv.PromotionPiece())
+
+	assert.True(t, New(60, 62, 0, 0, Castle).IsCastle())
+	assert.True(t, New(52, 36, 0, 0, DoublePush).IsDoublePush())
+}
+
+func TestSameSquares(t *testing.T) {
+	generated := New(52, 36, 0, 0, DoublePush)
+	assert.True(t, generated.SameSquares(*NewMove([]int{52, 36})))
+	assert.NotEqual(t, generated, *NewMove([]int{52, 36}))
+	assert.False(t, generated.SameSquares(*NewMove([]int{52, 44})))
+	assert.False(t, New(8, 0, 2, 0, 0).SameSquares(New(8, 0, 4, 0, 0)), "different promotion")
+}
+
+func TestMoveString(t *testing.T) {
+	assert.Equal(t, "e2e4", New(52, 36, 0, 0, DoublePush).String())
+	assert.Equal(t, "a7a8q", New(8, 0, 2, 0, 0).String())
+	assert.Equal(t, "a2a1q", New(48, 56, 2, 0, 0).String(), "black promotes in lower case too")
+	assert.Equal(t, "b7a8n", New(9, 0, 4, 5, Capture).String())
+	assert.Equal(t, "h2h1r", New(55, 63, 5, 0, 0).String())
+	assert.Equal(t, "c2c1b", New(50, 58, 3, 0, 0).String())
+}
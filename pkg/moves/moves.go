@@ -40,6 +40,11 @@ func (m *Moves) AddPromotionMove(origin int, dest int, promo int) {
 	m.mvs = append(m.mvs, *mv)
 }
 
+// Add appends mv to the list
+func (m *Moves) Add(mv Move) {
+	m.mvs = append(m.mvs, mv)
+}
+
 func (m *Moves) PopMove() *Move {
 	mv := &m.mvs[len(m.mvs)-1]
 	m.mvs = m.mvs[0 : len(m.mvs)-1]
@@ -60,7 +65,7 @@ func (m *Moves) Print() {
 func (m *Moves) AttackedSqsBb() *bitboard.Bitboard {
 	bb := new(bitboard.Bitboard)
 	for _, move := range m.mvs {
-		bb.SetBit(move.destination)
+		bb.SetBit(move.Destination())
 	}
 	return bb
 }
@@ -75,11 +80,11 @@ func (m *Moves) GetMoves() [][]int {
 
 func (m *Moves) FindMove(origin int, dest int, promotionPiece int) (Move, bool) {
 	for _, move := range m.mvs {
-		if move.origin == origin && move.destination == dest && move.promotion == promotionPiece {
+		if move.Origin() == origin && move.Destination() == dest && move.PromotionPiece() == promotionPiece {
 			return move, true
 		}
 	}
-	return Move{}, false
+	return 0, false
 }
 
 // NewBbFromMovesSlice takes a legal moves slice and returns a bitboard representing those moves
@@ -0,0 +1,180 @@
+// Package perftsuite checks move generation against perft suites in EPD format,
+// where every line is a position followed by the expected node counts:
+//
+//	rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - ;D1 20 ;D2 400 ;D3 8902
+package perftsuite
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/tonyOreglia/glee/pkg/engine"
+	"github.com/tonyOreglia/glee/pkg/position"
+)
+
+// Expectation is the node count expected at a single depth
+type Expectation struct {
+	Depth int
+	Nodes int
+}
+
+// Entry is a single position of the suite
+type Entry struct {
+	Line         int
+	Fen          string
+	Expectations []Expectation
+}
+
+// Result holds the outcome of running one entry. FailedDepth is the first depth whose
+// count differs from the expectation, and Divide the divide output at that depth.
+type Result struct {
+	Entry       Entry
+	Err         error
+	Nodes       int
+	FailedDepth int
+	Expected    int
+	Got         int
+	Divide      []engine.DivideCount
+}
+
+// Passed reports whether every expected count was matched
+func (r Result) Passed() bool {
+	return r.Err == nil && r.FailedDepth == 0
+}
+
+// ReadFile parses the EPD suite at path
+func ReadFile(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse reads an EPD suite, blank lines and lines starting with # are skipped
+func Parse(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entry, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", lineNumber, err)
+		}
+		entry.Line = lineNumber
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+func parseLine(line string) (Entry, error) {
+	fields := strings.Split(line, ";")
+	entry := Entry{Fen: strings.TrimSpace(fields[0])}
+	// EPD positions leave out the move counters
+	if len(strings.Fields(entry.Fen)) == 4 {
+		entry.Fen += " 0 1"
+	}
+	for _, field := range fields[1:] {
+		tokens := strings.Fields(field)
+		if len(tokens) == 0 {
+			continue
+		}
+		if len(tokens) != 2 || !strings.HasPrefix(tokens[0], "D") {
+			return entry, fmt.Errorf("invalid annotation %q", strings.TrimSpace(field))
+		}
+		depth, err := strconv.Atoi(tokens[0][1:])
+		if err != nil || depth < 1 {
+			return entry, fmt.Errorf("invalid depth %q", tokens[0])
+		}
+		nodes, err := strconv.Atoi(tokens[1])
+		if err != nil {
+			return entry, fmt.Errorf("invalid node count %q", tokens[1])
+		}
+		entry.Expectations = append(entry.Expectations, Expectation{Depth: depth, Nodes: nodes})
+	}
+	if len(entry.Expectations) == 0 {
+		return entry, fmt.Errorf("no node counts")
+	}
+	return entry, nil
+}
+
+// Run checks every entry up to maxDepth, zero checks every depth, with workers
+// positions running in parallel. Results are returned in the order of the entries.
+func Run(entries []Entry, maxDepth int, workers int) []Result {
+	if workers < 1 {
+		workers = 1
+	}
+	results := make([]Result, len(entries))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = runEntry(entries[i], maxDepth)
+			}
+		}()
+	}
+	for i := range entries {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+func runEntry(entry Entry, maxDepth int) Result {
+	result := Result{Entry: entry}
+	pos, err := position.NewPositionFen(entry.Fen)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	for _, expected := range entry.Expectations {
+		if maxDepth > 0 && expected.Depth > maxDepth {
+			continue
+		}
+		nodes := engine.Perft(pos, expected.Depth)
+		result.Nodes += nodes
+		if nodes != expected.Nodes {
+			result.FailedDepth, result.Expected, result.Got = expected.Depth, expected.Nodes, nodes
+			result.Divide = engine.Divide(pos, expected.Depth)
+			return result
+		}
+	}
+	return result
+}
+
+// Report writes a line for every entry and the divide output of failing entries.
+// It returns the number of entries that failed.
+func Report(w io.Writer, results []Result) int {
+	failures := 0
+	for _, result := range results {
+		switch {
+		case result.Err != nil:
+			failures++
+			fmt.Fprintf(w, "line %d: error %s: %s\n", result.Entry.Line, result.Entry.Fen, result.Err)
+		case result.FailedDepth != 0:
+			failures++
+			fmt.Fprintf(w, "line %d: FAIL %s depth %d expected %d got %d\n",
+				result.Entry.Line, result.Entry.Fen, result.FailedDepth, result.Expected, result.Got)
+			for _, count := range result.Divide {
+				fmt.Fprintf(w, "\t%s: %d\n", count.Move.String(), count.Nodes)
+			}
+		default:
+			fmt.Fprintf(w, "line %d: ok %s nodes %d\n", result.Entry.Line, result.Entry.Fen, result.Nodes)
+		}
+	}
+	fmt.Fprintf(w, "%d positions, %d failed\n", len(results), failures)
+	return failures
+}
@@ -0,0 +1,63 @@
+package perftsuite
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const suite = `# start position and kiwipete
+rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - ;D1 20 ;D2 400 ;D3 8902
+
+r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1 ;D1 48 ;D2 2039
+8/8/8/8/8/8/8/k6K w - - ;D1 3 ;D2 10
+`
+
+func TestParse(t *testing.T) {
+	entries, err := Parse(strings.NewReader(suite))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(entries))
+	assert.Equal(t, 2, entries[0].Line)
+	assert.Equal(t, "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", entries[0].Fen, "counters are added")
+	assert.Equal(t, []Expectation{{1, 20}, {2, 400}, {3, 8902}}, entries[0].Expectations)
+	assert.Equal(t, "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", entries[1].Fen)
+	assert.Equal(t, 4, entries[1].Line)
+
+	_, err = Parse(strings.NewReader("8/8/8/8/8/8/8/k6K w - - ;D1 x"))
+	assert.EqualError(t, err, `line 1: invalid node count "x"`)
+	_, err = Parse(strings.NewReader("8/8/8/8/8/8/8/k6K w - - ;bm Kg2"))
+	assert.EqualError(t, err, `line 1: invalid annotation "bm Kg2"`)
+	_, err = Parse(strings.NewReader("8/8/8/8/8/8/8/k6K w - -"))
+	assert.EqualError(t, err, "line 1: no node counts")
+}
+
+func TestRun(t *testing.T) {
+	entries, _ := Parse(strings.NewReader(suite))
+	results := Run(entries, 0, 2)
+	assert.True(t, results[0].Passed())
+	assert.Equal(t, 20+400+8902, results[0].Nodes)
+	assert.True(t, results[1].Passed())
+
+	// a bare king has three moves, so there are nine and not ten nodes at depth 2
+	assert.False(t, results[2].Passed())
+	assert.Equal(t, 2, results[2].FailedDepth)
+	assert.Equal(t, 10, results[2].Expected)
+	assert.Equal(t, 9, results[2].Got)
+	assert.Equal(t, 3, len(results[2].Divide))
+
+	results = Run(entries, 1, 1)
+	assert.True(t, results[2].Passed(), "depth 2 is not checked")
+	assert.Equal(t, 20, results[0].Nodes)
+}
+
+func TestReport(t *testing.T) {
+	entries, _ := Parse(strings.NewReader(suite))
+	out := new(bytes.Buffer)
+	failures := Report(out, Run(entries, 0, 1))
+	assert.Equal(t, 1, failures)
+	assert.Contains(t, out.String(), "line 2: ok")
+	assert.Contains(t, out.String(), "line 5: FAIL 8/8/8/8/8/8/8/k6K w - - 0 1 depth 2 expected 10 got 9\n\th1g1: 3\n")
+	assert.True(t, strings.HasSuffix(out.String(), "3 positions, 1 failed\n"))
+}
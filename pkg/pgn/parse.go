@@ -0,0 +1,284 @@
+package pgn
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/tonyOreglia/glee/pkg/position"
+	"github.com/tonyOreglia/glee/pkg/san"
+)
+
+// suffixNags maps the move suffix annotations to their numeric annotation glyphs
+var suffixNags = map[string]int{"!": 1, "?": 2, "!!": 3, "??": 4, "!?": 5, "?!": 6}
+
+var unescape = strings.NewReplacer(`\\`, `\`, `\"`, `"`)
+
+// ReadFile parses every game of the PGN file at path
+func ReadFile(path string) ([]*Game, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse reads every game of a PGN database. Games that end without a result are given Unknown.
+func Parse(r io.Reader) ([]*Game, error) {
+	text, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{text: string(text)}
+	var games []*Game
+	for p.skipSpace(); !p.done(); p.skipSpace() {
+		game, err := p.parseGame()
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", p.line(), err)
+		}
+		games = append(games, game)
+	}
+	return games, nil
+}
+
+// parser reads PGN text, offset is the next byte to read
+type parser struct {
+	text   string
+	offset int
+}
+
+func (p *parser) done() bool {
+	return p.offset >= len(p.text)
+}
+
+func (p *parser) peek() byte {
+	return p.text[p.offset]
+}
+
+// line returns the line number of the offset for error messages
+func (p *parser) line() int {
+	return strings.Count(p.text[:p.offset], "\n") + 1
+}
+
+// skipSpace skips white space, rest of line comments and escaped lines starting with %
+func (p *parser) skipSpace() {
+	for !p.done() {
+		c := p.peek()
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			p.offset++
+		case c == ';' || c == '%' && (p.offset == 0 || p.text[p.offset-1] == '\n'):
+			p.skipLine()
+		default:
+			return
+		}
+	}
+}
+
+func (p *parser) skipLine() {
+	if i := strings.IndexByte(p.text[p.offset:], '\n'); i >= 0 {
+		p.offset += i + 1
+		return
+	}
+	p.offset = len(p.text)
+}
+
+func (p *parser) parseGame() (*Game, error) {
+	game := &Game{}
+	for p.skipSpace(); !p.done() && p.peek() == '['; p.skipSpace() {
+		tag, err := p.parseTag()
+		if err != nil {
+			return nil, err
+		}
+		game.Tags = append(game.Tags, tag)
+	}
+	pos, err := game.StartingPosition()
+	if err != nil {
+		return nil, err
+	}
+	line, result, err := p.parseLine(pos, true)
+	if err != nil {
+		return nil, err
+	}
+	game.Comment, game.Moves, game.Result = line.Comment, line.Moves, result
+	return game, nil
+}
+
+// parseTag reads [Name "Value"], backslash escapes quotes and backslashes in the value.
+// The tag ends at the bracket after the closing quote, so several tags may share a line.
+func (p *parser) parseTag() (Tag, error) {
+	start := p.offset
+	invalid := func() (Tag, error) {
+		text := p.text[start:]
+		if end := strings.IndexByte(text, '\n'); end >= 0 {
+			text = text[:end]
+		}
+		p.offset = start
+		return Tag{}, fmt.Errorf("invalid tag %s", strings.TrimSpace(text))
+	}
+	p.offset++
+	p.skipBlanks()
+	name := p.word()
+	p.skipBlanks()
+	if name == "" || p.done() || p.peek() != '"' {
+		return invalid()
+	}
+	valueStart := p.offset + 1
+	for p.offset++; !p.done() && p.peek() != '"'; p.offset++ {
+		switch p.peek() {
+		case '\\':
+			p.offset++
+		case '\n':
+			return invalid()
+		}
+	}
+	if p.done() {
+		return invalid()
+	}
+	value := p.text[valueStart:p.offset]
+	p.offset++
+	p.skipBlanks()
+	if p.done() || p.peek() != ']' {
+		return invalid()
+	}
+	p.offset++
+	return Tag{Name: name, Value: unescape.Replace(value)}, nil
+}
+
+// skipBlanks skips spaces and tabs but not the end of the line
+func (p *parser) skipBlanks() {
+	for !p.done() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.offset++
+	}
+}
+
+// parseLine reads moves played from pos until the result of the game, or the closing
+// parenthesis when reading a variation. The moves are made on pos.
+func (p *parser) parseLine(pos *position.Position, mainLine bool) (Variation, string, error) {
+	var line Variation
+	for {
+		p.skipSpace()
+		if p.done() || mainLine && p.peek() == '[' {
+			if !mainLine {
+				return line, "", fmt.Errorf("unterminated variation")
+			}
+			return line, Unknown, nil
+		}
+		switch p.peek() {
+		case '{':
+			comment, err := p.parseComment()
+			if err != nil {
+				return line, "", err
+			}
+			if len(line.Moves) == 0 {
+				line.Comment = joinComments(line.Comment, comment)
+			} else {
+				last := &line.Moves[len(line.Moves)-1]
+				last.Comment = joinComments(last.Comment, comment)
+			}
+		case '(':
+			if len(line.Moves) == 0 {
+				return line, "", fmt.Errorf("variation before the first move")
+			}
+			p.offset++
+			variationPos := pos.Copy()
+			variationPos.UnmakeMove()
+			variation, _, err := p.parseLine(variationPos, false)
+			if err != nil {
+				return line, "", err
+			}
+			last := &line.Moves[len(line.Moves)-1]
+			last.Variations = append(last.Variations, variation)
+		case ')':
+			if mainLine {
+				return line, "", fmt.Errorf("unexpected )")
+			}
+			p.offset++
+			return line, "", nil
+		case '$':
+			p.offset++
+			nag, err := strconv.Atoi(p.word())
+			if err != nil || len(line.Moves) == 0 {
+				return line, "", fmt.Errorf("invalid annotation glyph")
+			}
+			last := &line.Moves[len(line.Moves)-1]
+			last.Nags = append(last.Nags, nag)
+		default:
+			word := p.word()
+			switch word {
+			case WhiteWins, BlackWins, Draw, Unknown:
+				if !mainLine {
+					return line, "", fmt.Errorf("result %s inside a variation", word)
+				}
+				return line, word, nil
+			case "":
+				return line, "", fmt.Errorf("unexpected %c", p.peek())
+			}
+			ply, err := parsePly(pos, word)
+			if err != nil {
+				return line, "", err
+			}
+			if ply.San != "" {
+				line.Moves = append(line.Moves, ply)
+			}
+		}
+	}
+}
+
+// parsePly reads a move that may be preceded by its move number and followed by
+// suffix annotations, e.g. 12.Nf3!?, and plays it on pos. A lone move number gives an empty ply.
+func parsePly(pos *position.Position, word string) (Ply, error) {
+	// castling written with zeros starts with digits as well, move numbers are followed by dots
+	if rest := strings.TrimLeft(word, "0123456789"); rest != word && strings.HasPrefix(rest, ".") {
+		word = strings.TrimLeft(rest, ".")
+	}
+	if word == "" {
+		return Ply{}, nil
+	}
+	text := strings.TrimRight(word, "!?")
+	var ply Ply
+	if suffix := word[len(text):]; suffix != "" {
+		nag, ok := suffixNags[suffix]
+		if !ok {
+			return ply, fmt.Errorf("invalid annotation %s", suffix)
+		}
+		ply.Nags = append(ply.Nags, nag)
+	}
+	mv, err := san.Parse(pos, text)
+	if err != nil {
+		return ply, err
+	}
+	ply.Move, ply.San = mv, san.Format(pos, mv)
+	pos.Move(mv)
+	return ply, nil
+}
+
+// word reads up to the next white space or movetext delimiter
+func (p *parser) word() string {
+	start := p.offset
+	for !p.done() && !strings.ContainsRune(" \t\r\n{}()$;[", rune(p.peek())) {
+		p.offset++
+	}
+	return p.text[start:p.offset]
+}
+
+func (p *parser) parseComment() (string, error) {
+	end := strings.IndexByte(p.text[p.offset:], '}')
+	if end < 0 {
+		return "", fmt.Errorf("unterminated comment")
+	}
+	comment := strings.Join(strings.Fields(p.text[p.offset+1:p.offset+end]), " ")
+	p.offset += end + 1
+	return comment, nil
+}
+
+func joinComments(comment string, next string) string {
+	if comment == "" {
+		return next
+	}
+	return comment + " " + next
+}
@@ -0,0 +1,147 @@
+// Package pgn reads and writes games in Portable Game Notation. Moves are checked
+// against the position they are played in, so a parsed game can be replayed.
+package pgn
+
+import (
+	"github.com/tonyOreglia/glee/pkg/generate"
+	"github.com/tonyOreglia/glee/pkg/moves"
+	"github.com/tonyOreglia/glee/pkg/position"
+	"github.com/tonyOreglia/glee/pkg/san"
+)
+
+// Game results as written at the end of the movetext
+const (
+	WhiteWins = "1-0"
+	BlackWins = "0-1"
+	Draw      = "1/2-1/2"
+	Unknown   = "*"
+)
+
+// Tag is a single tag pair, e.g. [Event "Casual game"]
+type Tag struct {
+	Name  string
+	Value string
+}
+
+// Ply is a single move of the movetext with its annotations. Variations are
+// alternatives to the move, played from the position before it.
+type Ply struct {
+	Move       moves.Move
+	San        string
+	Nags       []int
+	Comment    string
+	Variations []Variation
+}
+
+// Variation is a line of moves with the comment preceding its first move
+type Variation struct {
+	Comment string
+	Moves   []Ply
+}
+
+// Game is a parsed game. Comment precedes the first move.
+type Game struct {
+	Tags    []Tag
+	Comment string
+	Moves   []Ply
+	Result  string
+}
+
+// NewGame returns a game with the seven tag roster filled with unknown values
+func NewGame() *Game {
+	return &Game{
+		Tags: []Tag{
+			{"Event", "?"},
+			{"Site", "?"},
+			{"Date", "????.??.??"},
+			{"Round", "?"},
+			{"White", "?"},
+			{"Black", "?"},
+			{"Result", Unknown},
+		},
+		Result: Unknown,
+	}
+}
+
+// Tag returns the value of the tag called name, or an empty string
+func (g *Game) Tag(name string) string {
+	for _, tag := range g.Tags {
+		if tag.Name == name {
+			return tag.Value
+		}
+	}
+	return ""
+}
+
+// SetTag sets the tag called name, adding it after the existing tags when missing
+func (g *Game) SetTag(name string, value string) {
+	for i := range g.Tags {
+		if g.Tags[i].Name == name {
+			g.Tags[i].Value = value
+			return
+		}
+	}
+	g.Tags = append(g.Tags, Tag{name, value})
+}
+
+// SetResult sets the result of the movetext and the Result tag
+func (g *Game) SetResult(result string) {
+	g.Result = result
+	g.SetTag("Result", result)
+}
+
+// SetStartingPosition records that the game starts from pos instead of the initial position
+func (g *Game) SetStartingPosition(pos *position.Position) {
+	fen := pos.GetFenString()
+	if fen == position.StartingPosition().GetFenString() {
+		return
+	}
+	g.SetTag("SetUp", "1")
+	g.SetTag("FEN", fen)
+}
+
+// StartingPosition returns the position given by the FEN tag, or the initial position
+func (g *Game) StartingPosition() (*position.Position, error) {
+	if fen := g.Tag("FEN"); fen != "" {
+		return position.NewPositionFen(fen)
+	}
+	return position.StartingPosition(), nil
+}
+
+// AddMove appends mv, which must be legal in pos, to the main line and then plays it on pos
+func (g *Game) AddMove(pos *position.Position, mv moves.Move) {
+	g.Moves = append(g.Moves, Ply{Move: mv, San: san.Format(pos, mv)})
+	pos.Move(mv)
+}
+
+// Position replays the main line and returns the final position. Every move of the
+// main line can be taken back with UnmakeMove.
+func (g *Game) Position() (*position.Position, error) {
+	pos, err := g.StartingPosition()
+	if err != nil {
+		return nil, err
+	}
+	for _, ply := range g.Moves {
+		pos.Move(ply.Move)
+	}
+	return pos, nil
+}
+
+// Result returns the result of the game in pos if it is over: checkmate, stalemate,
+// threefold repetition, the fifty move rule or insufficient material. It returns
+// Unknown while the game goes on.
+func Result(pos *position.Position) string {
+	if generate.GenerateLegalMoves(pos).Length() == 0 {
+		switch {
+		case !pos.InCheck():
+			return Draw
+		case pos.IsWhitesTurn():
+			return BlackWins
+		}
+		return WhiteWins
+	}
+	if pos.IsRepetition() || pos.IsFiftyMoveDraw() || pos.IsInsufficientMaterial() {
+		return Draw
+	}
+	return Unknown
+}
@@ -0,0 +1,187 @@
+package pgn
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tonyOreglia/glee/pkg/generate"
+	"github.com/tonyOreglia/glee/pkg/moves"
+	"github.com/tonyOreglia/glee/pkg/position"
+)
+
+const database = `[Event "Casual game"]
+[Site "London"]
+[White "Anderssen, \"Adolf\""]
+[Black "Kieseritzky"]
+[Result "1-0"]
+
+{Opening comment} 1. e4 e5 2. f4 exf4 3. Bc4 Qh4+ $6 {a risky check} 4. Kf1 b5!?
+(4... Nf6 5. Nc3 (5. d3) 5... c6) 5.Bxb5 ; the rest of the line is ignored
+Nf6 1-0
+
+[Event "Fool's mate"]
+[Result "0-1"]
+
+1. f3 e5 2. g4 Qh4# 0-1
+
+[FEN "4k3/8/8/8/8/8/4P3/4K3 b - - 0 20"]
+[SetUp "1"]
+
+20... Kd7 21. e4 *
+`
+
+func TestParse(t *testing.T) {
+	games, err := Parse(strings.NewReader(database))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(games))
+
+	game := games[0]
+	assert.Equal(t, `Anderssen, "Adolf"`, game.Tag("White"))
+	assert.Equal(t, "", game.Tag("Round"))
+	assert.Equal(t, WhiteWins, game.Result)
+	assert.Equal(t, "Opening comment", game.Comment)
+	assert.Equal(t, 10, len(game.Moves))
+	assert.Equal(t, "Qh4+", game.Moves[5].San)
+	assert.Equal(t, []int{6}, game.Moves[5].Nags)
+	assert.Equal(t, "a risky check", game.Moves[5].Comment)
+	assert.Equal(t, []int{5}, game.Moves[7].Nags, "suffix annotations become glyphs")
+
+	variations := game.Moves[7].Variations
+	assert.Equal(t, 1, len(variations))
+	assert.Equal(t, []string{"Nf6", "Nc3", "c6"}, sans(variations[0].Moves))
+	assert.Equal(t, []string{"d3"}, sans(variations[0].Moves[1].Variations[0].Moves))
+
+	pos, err := game.Position()
+	assert.Nil(t, err)
+	assert.Equal(t, "rnb1kb1r/p1pp1ppp/5n2/1B6/4Pp1q/8/PPPP2PP/RNBQ1KNR w kq - 1 6", pos.GetFenString())
+
+	pos, _ = games[1].Position()
+	assert.Equal(t, BlackWins, Result(pos))
+
+	assert.Equal(t, Unknown, games[2].Result)
+	pos, _ = games[2].Position()
+	assert.Equal(t, "8/3k4/8/8/4P3/8/8/4K3 b - e3 0 21", pos.GetFenString())
+}
+
+func TestParseTagsOnOneLine(t *testing.T) {
+	games, err := Parse(strings.NewReader(`[Event "a"] [Site "b ] \"c\""]` + "\n\n1. e4 *"))
+	assert.Nil(t, err)
+	if assert.Equal(t, 1, len(games)) {
+		assert.Equal(t, "a", games[0].Tag("Event"))
+		assert.Equal(t, `b ] "c"`, games[0].Tag("Site"))
+		assert.Equal(t, 1, len(games[0].Moves))
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tt := map[string]string{
+		"1. e4 e5 2. Ke3 *":          "line 1: illegal move Ke3",
+		"1. e4 (1. d4":               "line 1: unterminated variation",
+		"1. e4 ) *":                  "line 1: unexpected )",
+		"1. e4 {unfinished":          "line 1: unterminated comment",
+		"[Event \"x\"\n\n1. e4 *":    "line 1: invalid tag [Event \"x\"",
+		"[Event \"x\" \"y\"] *":      "line 1: invalid tag [Event \"x\" \"y\"]",
+		"1. e4 (1. d4 1-0) *":        "line 1: result 1-0 inside a variation",
+		"(1. e4) *":                  "line 1: variation before the first move",
+		"[FEN \"8/8 w - - 0 1\"]\n*": "line 2: invalid fen: 2 ranks",
+	}
+	for text, expected := range tt {
+		_, err := Parse(strings.NewReader(text))
+		if assert.NotNil(t, err, text) {
+			assert.True(t, strings.HasPrefix(err.Error(), expected), err.Error())
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	games, _ := Parse(strings.NewReader(database))
+	expected := `[Event "Casual game"]
+[Site "London"]
+[White "Anderssen, \"Adolf\""]
+[Black "Kieseritzky"]
+[Result "1-0"]
+
+{Opening comment} 1. e4 e5 2. f4 exf4 3. Bc4 Qh4+ $6 {a risky check} 4. Kf1 b5
+$5 (4... Nf6 5. Nc3 (5. d3) 5... c6) 5. Bxb5 Nf6 1-0
+
+`
+	assert.Equal(t, expected, games[0].String())
+	assert.Equal(t, "[FEN \"4k3/8/8/8/8/8/4P3/4K3 b - - 0 20\"]\n[SetUp \"1\"]\n\n20... Kd7 21. e4 *\n\n", games[2].String())
+
+	reparsed, err := Parse(strings.NewReader(games[0].String()))
+	assert.Nil(t, err)
+	assert.Equal(t, games[0], reparsed[0])
+}
+
+func TestRecordGame(t *testing.T) {
+	game := NewGame()
+	pos, _ := position.NewPositionFen("7k/8/6K1/8/8/8/8/R7 w - - 0 1")
+	game.SetStartingPosition(pos)
+	game.AddMove(pos, findMove(t, pos, "a1a8"))
+	game.SetResult(Result(pos))
+	assert.Equal(t, WhiteWins, game.Result)
+	assert.Equal(t, "Ra8#", game.Moves[0].San)
+	assert.Equal(t, `[Event "?"]
+[Site "?"]
+[Date "????.??.??"]
+[Round "?"]
+[White "?"]
+[Black "?"]
+[Result "1-0"]
+[SetUp "1"]
+[FEN "7k/8/6K1/8/8/8/8/R7 w - - 0 1"]
+
+1. Ra8# 1-0
+
+`, game.String())
+
+	game = NewGame()
+	game.SetStartingPosition(position.StartingPosition())
+	assert.Equal(t, "", game.Tag("FEN"), "the initial position needs no FEN tag")
+}
+
+func TestResult(t *testing.T) {
+	tt := map[string]string{
+		"7k/5Q2/6K1/8/8/8/8/8 b - - 0 1":    Draw,
+		"7k/6Q1/6K1/8/8/8/8/8 b - - 0 1":    WhiteWins,
+		"7k/8/6K1/8/8/8/8/8 b - - 0 1":      Draw,
+		"7k/8/6K1/8/8/8/8/6R1 b - - 100 80": Draw,
+		"7k/8/6K1/8/8/8/8/6R1 b - - 0 1":    Unknown,
+	}
+	for fen, expected := range tt {
+		pos, _ := position.NewPositionFen(fen)
+		assert.Equal(t, expected, Result(pos), fen)
+	}
+}
+
+func TestWrapsLongMovetext(t *testing.T) {
+	game := NewGame()
+	pos := position.StartingPosition()
+	for i := 0; i < 10; i++ {
+		for _, mv := range []string{"g1f3", "g8f6", "f3g1", "f6g8"} {
+			game.AddMove(pos, findMove(t, pos, mv))
+		}
+	}
+	for _, line := range strings.Split(game.String(), "\n") {
+		assert.True(t, len(line) <= lineLength, line)
+	}
+}
+
+func sans(plies []Ply) []string {
+	var result []string
+	for _, ply := range plies {
+		result = append(result, ply.San)
+	}
+	return result
+}
+
+func findMove(t *testing.T, pos *position.Position, uci string) moves.Move {
+	for _, mv := range generate.GenerateLegalMoves(pos).GetMovesList() {
+		if mv.String() == uci {
+			return mv
+		}
+	}
+	t.Fatalf("%s is not legal", uci)
+	return 0
+}
@@ -0,0 +1,106 @@
+package pgn
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tonyOreglia/glee/pkg/position"
+)
+
+// lineLength is the longest movetext line written, as the PGN export format asks
+const lineLength = 80
+
+var escape = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// Write writes the game in PGN export format: the tags, a blank line, the movetext
+// wrapped at 80 characters ending in the result, and a blank line
+func (g *Game) Write(w io.Writer) error {
+	pos, err := g.StartingPosition()
+	if err != nil {
+		return err
+	}
+	for _, tag := range g.Tags {
+		if _, err := fmt.Fprintf(w, "[%s \"%s\"]\n", tag.Name, escape.Replace(tag.Value)); err != nil {
+			return err
+		}
+	}
+	// plies are counted from the initial position, white moves on even plies
+	ply := 2*(pos.MoveNumber()-1) + pos.GetActiveSide()
+	tokens := movetext(Variation{Comment: g.Comment, Moves: g.Moves}, ply)
+	result := g.Result
+	if result == "" {
+		result = Unknown
+	}
+	tokens = append(tokens, result)
+	_, err = fmt.Fprintf(w, "\n%s\n\n", wrap(tokens))
+	return err
+}
+
+// String returns the game in PGN export format
+func (g *Game) String() string {
+	out := new(strings.Builder)
+	if err := g.Write(out); err != nil {
+		return err.Error()
+	}
+	return out.String()
+}
+
+// movetext returns the tokens of line, whose first move is played at ply. Black moves
+// get their number when they follow a comment or a variation.
+func movetext(line Variation, ply int) []string {
+	var tokens []string
+	numbered := true
+	if line.Comment != "" {
+		tokens = append(tokens, "{"+line.Comment+"}")
+	}
+	for i, move := range line.Moves {
+		number := (ply+i)/2 + 1
+		switch {
+		case (ply+i)%2 == position.White:
+			tokens = append(tokens, fmt.Sprintf("%d.", number))
+		case numbered:
+			tokens = append(tokens, fmt.Sprintf("%d...", number))
+		}
+		tokens = append(tokens, move.San)
+		for _, nag := range move.Nags {
+			tokens = append(tokens, fmt.Sprintf("$%d", nag))
+		}
+		numbered = false
+		if move.Comment != "" {
+			tokens = append(tokens, "{"+move.Comment+"}")
+			numbered = true
+		}
+		for _, variation := range move.Variations {
+			variationTokens := movetext(variation, ply+i)
+			if len(variationTokens) == 0 {
+				continue
+			}
+			variationTokens[0] = "(" + variationTokens[0]
+			variationTokens[len(variationTokens)-1] += ")"
+			tokens = append(tokens, variationTokens...)
+			numbered = true
+		}
+	}
+	return tokens
+}
+
+// wrap joins the tokens with spaces, starting a new line before a word would pass lineLength
+func wrap(tokens []string) string {
+	out := new(strings.Builder)
+	length := 0
+	for _, token := range tokens {
+		for _, word := range strings.Fields(token) {
+			if length > 0 && length+1+len(word) > lineLength {
+				out.WriteByte('\n')
+				length = 0
+			} else if length > 0 {
+				out.WriteByte(' ')
+				length++
+			}
+			out.WriteString(word)
+			length += len(word)
+		}
+	}
+	return out.String()
+}
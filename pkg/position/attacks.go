@@ -0,0 +1,41 @@
+package position
+
+import "github.com/tonyOreglia/glee/pkg/bitboard"
+
+// AttackersTo returns the pieces of side that attack sq
+func (p *Position) AttackersTo(sq int, side int) *bitboard.Bitboard {
+	return bitboard.NewBitboard(p.attackersTo(sq, side))
+}
+
+// IsSquareAttacked reports whether any piece of side attacks sq
+func (p *Position) IsSquareAttacked(sq int, side int) bool {
+	return p.attackersTo(sq, side) != 0
+}
+
+// Checkers returns the pieces giving check to the king of the side to move
+func (p *Position) Checkers() *bitboard.Bitboard {
+	return bitboard.NewBitboard(p.checkers())
+}
+
+// InCheck reports whether the king of the side to move is attacked
+func (p *Position) InCheck() bool {
+	return p.checkers() != 0
+}
+
+func (p *Position) checkers() uint64 {
+	return p.attackersTo(p.bitboards[p.activeSide][King].Lsb(), p.activeSide^1)
+}
+
+// attackersTo traces every piece type backwards from sq, a knight on sq
+// attacks exactly the squares of the knights attacking sq
+func (p *Position) attackersTo(sq int, side int) uint64 {
+	bbs := p.bitboards[side]
+	occSqsBb := p.bitboards[White][OccupiedSqs].Value() | p.bitboards[Black][OccupiedSqs].Value()
+	bishopsQueens := bbs[Bishops].Value() | bbs[Queen].Value()
+	rooksQueens := bbs[Rooks].Value() | bbs[Queen].Value()
+	return ht.KnightAttackBbHash[sq]&bbs[Knights].Value() |
+		ht.LegalKingMovesNoCastlingBbHash[sq]&bbs[King].Value() |
+		ht.PawnAttacksBbHash[side^1][sq]&bbs[Pawns].Value() |
+		ht.BishopAttacks(sq, occSqsBb)&bishopsQueens |
+		ht.RookAttacks(sq, occSqsBb)&rooksQueens
+}
@@ -0,0 +1,46 @@
+package position
+
+// lightSqsBb has a bit set for every light square, a8 is light
+const lightSqsBb = uint64(0xAA55AA55AA55AA55)
+
+// RepetitionCount returns how many times the position occurred before with the same side to move.
+// Only positions since the last capture or pawn move can repeat.
+func (p *Position) RepetitionCount() int {
+	count := 0
+	for i := 2; i <= p.halfMoveCt && i <= len(p.history); i += 2 {
+		if p.history[len(p.history)-i].hash == p.hash {
+			count++
+		}
+	}
+	return count
+}
+
+// IsRepetition reports whether the position has occurred three times, a draw by threefold repetition
+func (p *Position) IsRepetition() bool {
+	return p.RepetitionCount() >= 2
+}
+
+// IsFiftyMoveDraw reports whether fifty moves have been played by each side without a capture or pawn move
+func (p *Position) IsFiftyMoveDraw() bool {
+	return p.halfMoveCt >= 100
+}
+
+// IsInsufficientMaterial reports whether neither side can possibly mate: king against king
+// and a single minor piece, or kings and bishops that all stand on squares of one color
+func (p *Position) IsInsufficientMaterial() bool {
+	minorPieces := 0
+	knights := 0
+	var bishopsBb uint64
+	for side := White; side <= Black; side++ {
+		if p.bitboards[side][Pawns].Value()|p.bitboards[side][Rooks].Value()|p.bitboards[side][Queen].Value() != 0 {
+			return false
+		}
+		knights += p.bitboards[side][Knights].PopulationCount()
+		bishopsBb |= p.bitboards[side][Bishops].Value()
+		minorPieces += p.bitboards[side][Knights].PopulationCount() + p.bitboards[side][Bishops].PopulationCount()
+	}
+	if minorPieces <= 1 {
+		return true
+	}
+	return knights == 0 && (bishopsBb&lightSqsBb == 0 || bishopsBb&^lightSqsBb == 0)
+}
@@ -5,7 +5,6 @@ package position
 import (
 	"errors"
 	"fmt"
-	"log"
 	"strconv"
 	"strings"
 
@@ -42,30 +41,58 @@ type Position struct {
 	castlingRights []bitboard.Bitboard
 	activeSide     int
 	enPassanteSq   int
-	moveCt         int
+	// moveCt is the full move number, incremented after black moves
+	moveCt int
+	// halfMoveCt counts the half moves since the last capture or pawn move
+	halfMoveCt int
+	hash       uint64
+	// history holds what is needed to take back every move made, most recent last
+	history []undo
+}
+
+// undo is the state a move destroys, saved by MakeMove so UnmakeMove can restore it
+type undo struct {
+	// move carries the squares, promotion, captured piece and flags of the move made
+	move           moves.Move
+	castlingRights [2]uint64
+	enPassanteSq   int
 	halfMoveCt     int
-	previousPos    *Position
+	hash           uint64
 }
 
+// historySize is the number of moves that can be made before the undo stack has to grow
+const historySize = 512
+
 func StartingPosition() *Position {
 	p, _ := NewPositionFen("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
 	return p
 }
 
-// NewPositionFen constructs Position struct instance from Forth-Edwards Notation string
+// NewPositionFen constructs Position struct instance from Forth-Edwards Notation string.
+// The move counters may be left out. It returns an error describing the problem when
+// the FEN is malformed or the position could not arise in a game.
 func NewPositionFen(fen string) (*Position, error) {
 	p := new(Position)
 	p.bitboards[0] = make([]bitboard.Bitboard, 7)
 	p.bitboards[1] = make([]bitboard.Bitboard, 7)
 	p.castlingRights = make([]bitboard.Bitboard, 2)
-	Position, activeSide, castlingRights, enPassanteSq, moveCount, halfMoveCount := getFenStringTokens(fen)
-	p.setBitboardsFromFen(Position, activeSide)
+	Position, activeSide, castlingRights, enPassanteSq, halfMoveCount, moveCount, err := getFenStringTokens(fen)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.setBitboardsFromFen(Position); err != nil {
+		return nil, err
+	}
 	p.setActiveSide(activeSide)
 	p.setCastlingRightsFromFen(castlingRights)
 	p.enPassanteSq = enPassanteSq
 	p.moveCt = moveCount
 	p.halfMoveCt = halfMoveCount
-	p.previousPos = nil
+	p.history = make([]undo, 0, historySize)
+	if err := p.validate(castlingRights); err != nil {
+		return nil, err
+	}
+	p.hash = p.CalculateHash()
 	return p, nil
 }
 
@@ -78,6 +105,8 @@ func (p *Position) Copy() *Position {
 	copy(pCopy.bitboards[0], p.bitboards[0])
 	copy(pCopy.bitboards[1], p.bitboards[1])
 	copy(pCopy.castlingRights, p.castlingRights)
+	pCopy.history = make([]undo, len(p.history), len(p.history)+historySize)
+	copy(pCopy.history, p.history)
 	return pCopy
 }
 
@@ -115,6 +144,11 @@ func (p *Position) GetActiveSide() int {
 	return p.activeSide
 }
 
+// MoveNumber returns the full move number, starting at 1 and incremented after black moves
+func (p *Position) MoveNumber() int {
+	return p.moveCt
+}
+
 func (p *Position) GetActiveSideCastlingRightsBb() *bitboard.Bitboard {
 	return &p.castlingRights[p.activeSide]
 }
@@ -135,6 +169,21 @@ func (p *Position) InactiveSideKingBb() bitboard.Bitboard {
 	return p.bitboards[White][King]
 }
 
+// PieceAt returns the piece and side occupying sq, piece is zero when sq is empty
+func (p *Position) PieceAt(sq int) (piece int, side int) {
+	for side = White; side <= Black; side++ {
+		if p.bitboards[side][OccupiedSqs].BitIsNotSet(sq) {
+			continue
+		}
+		for piece = King; piece <= Pawns; piece++ {
+			if p.bitboards[side][piece].BitIsSet(sq) {
+				return piece, side
+			}
+		}
+	}
+	return 0, White
+}
+
 func (p *Position) WhiteKingBb() bitboard.Bitboard {
 	return p.bitboards[White][King]
 }
@@ -151,79 +200,172 @@ func (p *Position) GetBlackBitboards() []bitboard.Bitboard {
 	return p.bitboards[Black]
 }
 
-func (p *Position) UnMakeMove() *Position {
-	return p.previousPos
-}
-
+// Move makes mv in place, including promotion
 func (p *Position) Move(mv moves.Move) {
-	sideToMove := p.activeSide
-	p.MakeMove(mv.Origin(), mv.Destination())
-	if mv.PromotionPiece() != 0 {
-		p.promotePawn(mv.Destination(), mv.PromotionPiece(), sideToMove)
-	}
+	p.makeMove(mv.Origin(), mv.Destination(), mv.PromotionPiece())
 }
 
-func (p *Position) promotePawn(sq int, piece int, sideToMove int) {
-	p.bitboards[sideToMove][piece].SetBit(sq)
-	p.bitboards[sideToMove][Pawns].RemoveBit(sq)
-	p.updatedOccupiedSqBitboard(sideToMove)
+// MakeMove makes the move from originIndex to terminusIndex in place.
+// The state the move destroys is pushed on the undo stack for UnmakeMove.
+func (p *Position) MakeMove(originIndex int, terminusIndex int) {
+	p.makeMove(originIndex, terminusIndex, 0)
 }
 
-func (p *Position) MakeMove(originIndex int, terminusIndex int) {
-	p.previousPos = p.Copy()
+func (p *Position) makeMove(originIndex int, terminusIndex int, promotion int) {
+	p.history = append(p.history, undo{
+		castlingRights: [2]uint64{p.castlingRights[White].Value(), p.castlingRights[Black].Value()},
+		enPassanteSq:   p.enPassanteSq,
+		halfMoveCt:     p.halfMoveCt,
+		hash:           p.hash,
+	})
+	sideToMove := p.activeSide
+	var flags moves.Flag
+	// castling rights and en passante are hashed back in once the move is complete
+	p.hash ^= p.castlingHash() ^ p.enPassanteHash()
 	// double pawn push move, set en passante
 	p.enPassanteSq = 64
 	doublePawnPush := p.bitboards[p.activeSide][Pawns].BitIsSet(originIndex) && (terminusIndex-originIndex == -16 || terminusIndex-originIndex == 16)
 	if doublePawnPush {
 		p.enPassanteSq = (terminusIndex-originIndex)/2 + originIndex
+		flags |= moves.DoublePush
 	}
-	movingPiece := p.updateMovingSidesBbs(originIndex, terminusIndex)
+	movingPiece := p.movePiece(originIndex, terminusIndex)
 
 	if movingPiece == King {
 		diff := terminusIndex - originIndex
 		// king side castle move
 		if diff == 2 {
-			_ = p.updateMovingSidesBbs(terminusIndex+1, terminusIndex-1)
+			_ = p.movePiece(terminusIndex+1, terminusIndex-1)
+			flags |= moves.Castle
 		}
 		// queen side castle move
 		if diff == -2 {
-			_ = p.updateMovingSidesBbs(terminusIndex-2, terminusIndex+1)
+			_ = p.movePiece(terminusIndex-2, terminusIndex+1)
+			flags |= moves.Castle
 		}
 		p.revokeQueenSideCastlingRight()
 		p.revokeKingSideCastlingRight()
 	}
-	if movingPiece == Rooks {
-		if originIndex == 0 || originIndex == 56 {
-			p.revokeQueenSideCastlingRight()
-		}
-		if originIndex == 7 || originIndex == 63 {
-			p.revokeKingSideCastlingRight()
-		}
+	// a move from or to a rook's corner square means the rook has moved or been captured
+	p.revokeCastlingRightOfRookSq(originIndex)
+	p.revokeCastlingRightOfRookSq(terminusIndex)
+	if promotion != 0 {
+		p.promotePawn(terminusIndex, promotion, sideToMove)
 	}
 	p.updatedOccupiedSqBitboard(p.activeSide)
 	p.switchActiveSide()
-	attackedPiece := p.removeAttackedPieceFromBbs(terminusIndex)
-	if attackedPiece == Rooks {
-		if (terminusIndex % 8) == 7 {
-			p.revokeKingSideCastlingRight()
-		} else if (terminusIndex % 8) == 0 {
-			p.revokeQueenSideCastlingRight()
-		}
-	}
+	attackedPiece := p.capturePiece(terminusIndex)
 	enPassanteAttack := movingPiece == Pawns && (terminusIndex-originIndex)%8 != 0 && attackedPiece == 0
 	if enPassanteAttack {
 		capturnedPawnIndex := terminusIndex + 8
 		if originIndex >= 32 && originIndex < 40 {
 			capturnedPawnIndex = terminusIndex - 8
 		}
-		p.removeAttackedPieceFromBbs(capturnedPawnIndex)
+		attackedPiece = p.capturePiece(capturnedPawnIndex)
+		flags |= moves.EnPassant
+	}
+	if attackedPiece != 0 {
+		flags |= moves.Capture
 	}
 	p.updatedOccupiedSqBitboard(p.activeSide)
-	if p.activeSide == Black {
+	p.hash ^= p.castlingHash() ^ p.enPassanteHash()
+	p.halfMoveCt++
+	if movingPiece == Pawns || attackedPiece != 0 {
+		p.halfMoveCt = 0
+	}
+	if p.activeSide == White {
+		p.moveCt++
+	}
+	p.history[len(p.history)-1].move = moves.New(originIndex, terminusIndex, promotion, attackedPiece, flags)
+}
+
+// MakeNullMove passes the turn to the other side without moving, UnmakeMove takes it back.
+// The en passante square is cleared and the half move clock restarts, so that no
+// repetition is counted across the null move.
+func (p *Position) MakeNullMove() {
+	p.history = append(p.history, undo{
+		castlingRights: [2]uint64{p.castlingRights[White].Value(), p.castlingRights[Black].Value()},
+		enPassanteSq:   p.enPassanteSq,
+		halfMoveCt:     p.halfMoveCt,
+		hash:           p.hash,
+	})
+	p.hash ^= p.enPassanteHash()
+	p.enPassanteSq = 64
+	p.switchActiveSide()
+	p.halfMoveCt = 0
+	if p.activeSide == White {
 		p.moveCt++
 	}
 }
 
+// UnmakeMove takes back the last move made, it reports false when there is no move to take back
+func (p *Position) UnmakeMove() bool {
+	if len(p.history) == 0 {
+		return false
+	}
+	last := &p.history[len(p.history)-1]
+	mv := last.move
+	if mv == 0 {
+		p.unmakeNullMove(last)
+		return true
+	}
+	origin, terminus := mv.Origin(), mv.Destination()
+	opponent := p.activeSide
+	p.activeSide ^= 1
+	if p.activeSide == Black {
+		p.moveCt--
+	}
+	if mv.PromotionPiece() != 0 {
+		p.bitboards[p.activeSide][mv.PromotionPiece()].RemoveBit(terminus)
+		p.bitboards[p.activeSide][Pawns].SetBit(terminus)
+	}
+	p.updateMovingSidesBbs(terminus, origin)
+	if mv.IsCastle() {
+		if terminus > origin {
+			p.updateMovingSidesBbs(terminus-1, terminus+1)
+		} else {
+			p.updateMovingSidesBbs(terminus+1, terminus-2)
+		}
+	}
+	if mv.IsCapture() {
+		capturedSq := terminus
+		if mv.IsEnPassant() {
+			capturedSq = terminus + 8
+			if opponent == White {
+				capturedSq = terminus - 8
+			}
+		}
+		p.bitboards[opponent][mv.CapturedPiece()].SetBit(capturedSq)
+		p.updatedOccupiedSqBitboard(opponent)
+	}
+	p.updatedOccupiedSqBitboard(p.activeSide)
+	p.castlingRights[White].Set(last.castlingRights[White])
+	p.castlingRights[Black].Set(last.castlingRights[Black])
+	p.enPassanteSq = last.enPassanteSq
+	p.halfMoveCt = last.halfMoveCt
+	p.hash = last.hash
+	p.history = p.history[:len(p.history)-1]
+	return true
+}
+
+func (p *Position) unmakeNullMove(last *undo) {
+	p.activeSide ^= 1
+	if p.activeSide == Black {
+		p.moveCt--
+	}
+	p.enPassanteSq = last.enPassanteSq
+	p.halfMoveCt = last.halfMoveCt
+	p.hash = last.hash
+	p.history = p.history[:len(p.history)-1]
+}
+
+func (p *Position) promotePawn(sq int, piece int, sideToMove int) {
+	p.bitboards[sideToMove][piece].SetBit(sq)
+	p.bitboards[sideToMove][Pawns].RemoveBit(sq)
+	p.togglePieceHash(sideToMove, Pawns, sq)
+	p.togglePieceHash(sideToMove, piece, sq)
+}
+
 // MakeMove updates position with single chess move
 func (p *Position) MakeMoveAlgebraic(origin string, terminus string) {
 	originIndex, _ := moves.ConvertAlgebriacToIndex(origin)
@@ -251,6 +393,7 @@ func (p *Position) IsKingMove(mv moves.Move) bool {
 }
 
 func (p *Position) switchActiveSide() {
+	p.hash ^= zobrist.blackToMove
 	if p.activeSide == White {
 		p.activeSide = Black
 	} else {
@@ -258,6 +401,13 @@ func (p *Position) switchActiveSide() {
 	}
 }
 
+// capturePiece removes the active side's piece on terminus and updates the hash
+func (p *Position) capturePiece(terminus int) int {
+	piece := p.removeAttackedPieceFromBbs(terminus)
+	p.togglePieceHash(p.activeSide, piece, terminus)
+	return piece
+}
+
 func (p *Position) removeAttackedPieceFromBbs(terminus int) int {
 	switch {
 	case p.bitboards[p.activeSide][Pawns].BitIsSet(terminus):
@@ -282,6 +432,14 @@ func (p *Position) removeAttackedPieceFromBbs(terminus int) int {
 	return 0
 }
 
+// movePiece moves the active side's piece from origin to terminus and updates the hash
+func (p *Position) movePiece(origin int, terminus int) int {
+	piece := p.updateMovingSidesBbs(origin, terminus)
+	p.togglePieceHash(p.activeSide, piece, origin)
+	p.togglePieceHash(p.activeSide, piece, terminus)
+	return piece
+}
+
 func (p *Position) updateMovingSidesBbs(origin int, terminus int) int {
 	switch {
 	case p.bitboards[p.activeSide][Pawns].BitIsSet(origin):
@@ -321,8 +479,8 @@ func (p *Position) GetFenString() string {
 	fenPosition += " " + activeSideString +
 		" " + castlingRightsString +
 		" " + enPassanteSqFenString +
-		" " + strconv.Itoa(p.moveCt) +
-		" " + strconv.Itoa(p.halfMoveCt)
+		" " + strconv.Itoa(p.halfMoveCt) +
+		" " + strconv.Itoa(p.moveCt)
 	return fenPosition
 }
 
@@ -388,17 +546,33 @@ func (p *Position) setActiveSide(activeSide int) {
 }
 
 func (p *Position) revokeQueenSideCastlingRight() {
-	if p.activeSide == White && p.castlingRights[White].BitIsNotSet(WhiteKingSideCastlingRightsBit) {
-		p.castlingRights[p.activeSide].SetBit(WhiteQueenSideCastlingRightsBit)
+	if p.activeSide == White {
+		p.castlingRights[White].SetBit(WhiteQueenSideCastlingRightsBit)
+		return
 	}
-	p.castlingRights[p.activeSide].SetBit(BlackQueenSideCastlingRightsBit)
+	p.castlingRights[Black].SetBit(BlackQueenSideCastlingRightsBit)
 }
 
 func (p *Position) revokeKingSideCastlingRight() {
 	if p.activeSide == White {
-		p.castlingRights[p.activeSide].SetBit(WhiteKingSideCastlingRightsBit)
+		p.castlingRights[White].SetBit(WhiteKingSideCastlingRightsBit)
+		return
+	}
+	p.castlingRights[Black].SetBit(BlackKingSideCastlingRightsBit)
+}
+
+// revokeCastlingRightOfRookSq revokes the castling right that needs a rook on corner square sq
+func (p *Position) revokeCastlingRightOfRookSq(sq int) {
+	switch sq {
+	case 56:
+		p.castlingRights[White].SetBit(WhiteQueenSideCastlingRightsBit)
+	case 63:
+		p.castlingRights[White].SetBit(WhiteKingSideCastlingRightsBit)
+	case 0:
+		p.castlingRights[Black].SetBit(BlackQueenSideCastlingRightsBit)
+	case 7:
+		p.castlingRights[Black].SetBit(BlackKingSideCastlingRightsBit)
 	}
-	p.castlingRights[p.activeSide].SetBit(BlackKingSideCastlingRightsBit)
 }
 
 func (p *Position) setCastlingRightsFromFen(castlingRights string) {
@@ -421,104 +595,136 @@ func (p *Position) setCastlingRightsFromFen(castlingRights string) {
 	}
 }
 
-func (p *Position) setBitboardsFromFen(fenPosition string, activeSide int) {
-	var boardIndex int
-	for fenStringIndex := 0; fenStringIndex < len(fenPosition); fenStringIndex++ {
-		letter := string(fenPosition[fenStringIndex])
-		switch letter {
-		case "p":
-			p.bitboards[Black][Pawns].SetBit(boardIndex)
-		case "r":
-			p.bitboards[Black][Rooks].SetBit(boardIndex)
-		case "n":
-			p.bitboards[Black][Knights].SetBit(boardIndex)
-		case "b":
-			p.bitboards[Black][Bishops].SetBit(boardIndex)
-		case "q":
-			p.bitboards[Black][Queen].SetBit(boardIndex)
-		case "k":
-			p.bitboards[Black][King].SetBit(boardIndex)
-		case "P":
-			p.bitboards[White][Pawns].SetBit(boardIndex)
-		case "R":
-			p.bitboards[White][Rooks].SetBit(boardIndex)
-		case "N":
-			p.bitboards[White][Knights].SetBit(boardIndex)
-		case "B":
-			p.bitboards[White][Bishops].SetBit(boardIndex)
-		case "Q":
-			p.bitboards[White][Queen].SetBit(boardIndex)
-		case "K":
-			p.bitboards[White][King].SetBit(boardIndex)
-		case "/":
-			boardIndex--
-		case "1":
-		case "2":
-			boardIndex++
-		case "3":
-			boardIndex += 2
-		case "4":
-			boardIndex += 3
-		case "5":
-			boardIndex += 4
-		case "6":
-			boardIndex += 5
-		case "7":
-			boardIndex += 6
-		case "8":
-			boardIndex += 7
+// fenPieces maps the piece letters of a FEN to side and piece
+var fenPieces = map[rune][2]int{
+	'P': {White, Pawns}, 'R': {White, Rooks}, 'N': {White, Knights}, 'B': {White, Bishops}, 'Q': {White, Queen}, 'K': {White, King},
+	'p': {Black, Pawns}, 'r': {Black, Rooks}, 'n': {Black, Knights}, 'b': {Black, Bishops}, 'q': {Black, Queen}, 'k': {Black, King},
+}
+
+// setBitboardsFromFen places the pieces of the piece placement field, ranks are given from 8 to 1
+func (p *Position) setBitboardsFromFen(fenPosition string) error {
+	ranks := strings.Split(fenPosition, "/")
+	if len(ranks) != 8 {
+		return fmt.Errorf("invalid fen: %d ranks in piece placement %s", len(ranks), fenPosition)
+	}
+	for rankIndex, rank := range ranks {
+		file := 0
+		for _, letter := range rank {
+			if letter >= '1' && letter <= '8' {
+				file += int(letter - '0')
+				continue
+			}
+			piece, ok := fenPieces[letter]
+			if !ok {
+				return fmt.Errorf("invalid fen: unknown piece %q", letter)
+			}
+			if file < 8 {
+				p.bitboards[piece[0]][piece[1]].SetBit(rankIndex*8 + file)
+			}
+			file++
+		}
+		if file != 8 {
+			return fmt.Errorf("invalid fen: rank %d has %d squares", 8-rankIndex, file)
 		}
-		boardIndex++
 	}
 	p.updatedOccupiedSqBitboard(White)
 	p.updatedOccupiedSqBitboard(Black)
+	return nil
 }
 
-func getFenStringTokens(fen string) (string, int, string, int, int, int) {
-	var activeSide int
-	fenTokens := strings.Split(fen, " ")
-	moveCount, err := strconv.Atoi(fenTokens[4])
-	if err != nil {
-		log.Fatal(err)
+// getFenStringTokens splits fen into its fields, the half move clock comes before the full move number.
+// The move counters default to 0 and 1 when left out.
+func getFenStringTokens(fen string) (string, int, string, int, int, int, error) {
+	fenTokens := strings.Fields(fen)
+	if len(fenTokens) == 4 {
+		fenTokens = append(fenTokens, "0", "1")
 	}
-	halfMoveCount, err := strconv.Atoi(fenTokens[5])
-	if err != nil {
-		log.Fatal(err)
+	if len(fenTokens) != 6 {
+		return "", 0, "", 0, 0, 0, fmt.Errorf("invalid fen: expected 6 fields, got %d in %q", len(fenTokens), fen)
 	}
-	enPassnantSq, _ := moves.ConvertAlgebriacToIndex(fenTokens[3])
+	var activeSide int
 	switch fenTokens[1] {
 	case "w":
 		activeSide = White
 	case "b":
 		activeSide = Black
 	default:
-		log.Fatal(errors.New("Active side encoded in Fen must be either 'w' or 'b'"))
+		return "", 0, "", 0, 0, 0, fmt.Errorf("invalid fen: side to move must be w or b, got %s", fenTokens[1])
+	}
+	castlingRights := fenTokens[2]
+	if castlingRights != "-" {
+		for i, right := range castlingRights {
+			if !strings.ContainsRune("KQkq", right) || strings.ContainsRune(castlingRights[:i], right) {
+				return "", 0, "", 0, 0, 0, fmt.Errorf("invalid fen: castling rights %s", castlingRights)
+			}
+		}
 	}
-	err = validateFenTokens(fenTokens[0], activeSide, fenTokens[2], enPassnantSq, moveCount, halfMoveCount)
-	if err != nil {
-		log.Fatal(err)
+	enPassantSq := 64
+	if fenTokens[3] != "-" {
+		ep := fenTokens[3]
+		if len(ep) != 2 || ep[0] < 'a' || ep[0] > 'h' || ep[1] < '1' || ep[1] > '8' {
+			return "", 0, "", 0, 0, 0, fmt.Errorf("invalid fen: en passant square %s", ep)
+		}
+		enPassantSq, _ = moves.ConvertAlgebriacToIndex(ep)
+	}
+	halfMoveCount, err := strconv.Atoi(fenTokens[4])
+	if err != nil || halfMoveCount < 0 {
+		return "", 0, "", 0, 0, 0, fmt.Errorf("invalid fen: half move clock %s", fenTokens[4])
 	}
-	return fenTokens[0], activeSide, fenTokens[2], enPassnantSq, moveCount, halfMoveCount
+	moveCount, err := strconv.Atoi(fenTokens[5])
+	if err != nil || moveCount < 1 {
+		return "", 0, "", 0, 0, 0, fmt.Errorf("invalid fen: full move number %s", fenTokens[5])
+	}
+	return fenTokens[0], activeSide, castlingRights, enPassantSq, halfMoveCount, moveCount, nil
 }
 
-func validateFenTokens(Position string, activeSide int, castlingRights string, enPassanteSq int, moveCount int, halfMoveCount int) error {
-	if moveCount < 0 {
-		return errors.New("Move count encoded in FEN string is less than zero")
-	}
-	if halfMoveCount < 0 {
-		return errors.New("Half move count encoded in FEN string is less than zero")
+// backRanksBb has a bit set for every square of the first and last rank
+const backRanksBb = uint64(0xFF000000000000FF)
+
+// castlingSquares lists for every castling right the squares of the king and rook it needs
+var castlingSquares = map[rune]struct{ side, king, rook int }{
+	'K': {White, 60, 63},
+	'Q': {White, 60, 56},
+	'k': {Black, 4, 7},
+	'q': {Black, 4, 0},
+}
+
+// validate checks that the position could arise in a game: one king each, no pawns on the
+// first or last rank, castling rights matching kings and rooks on their squares, an en passant
+// square behind a pawn that just moved two squares, and the side that just moved not in check
+func (p *Position) validate(castlingRights string) error {
+	for side, name := range []string{"white", "black"} {
+		if kings := p.bitboards[side][King].PopulationCount(); kings != 1 {
+			return fmt.Errorf("invalid fen: %s has %d kings", name, kings)
+		}
 	}
-	if len(Position) > 71 {
-		return errors.New("Position string encoded in FEN is longer than 71 characters in length")
+	if (p.bitboards[White][Pawns].Value()|p.bitboards[Black][Pawns].Value())&backRanksBb != 0 {
+		return errors.New("invalid fen: pawn on the first or last rank")
 	}
-	if len(Position) < 15 {
-		return errors.New("Position string encoded in FEN is shorter than 15 characters in length")
+	if castlingRights != "-" {
+		for _, right := range castlingRights {
+			sqs := castlingSquares[right]
+			if !p.bitboards[sqs.side][King].BitIsSet(sqs.king) || !p.bitboards[sqs.side][Rooks].BitIsSet(sqs.rook) {
+				return fmt.Errorf("invalid fen: castling right %c without king and rook on their squares", right)
+			}
+		}
 	}
-	if enPassanteSq > 64 {
-		return errors.New("Invalid en passante square encoded in FEN")
+	if p.enPassanteSq != 64 {
+		// the pawn that moved two squares stands in front of the en passant square,
+		// which is on the sixth rank with white to move and on the third with black to move
+		row, pawnSq, originSq := 2, p.enPassanteSq+8, p.enPassanteSq-8
+		if p.activeSide == Black {
+			row, pawnSq, originSq = 5, p.enPassanteSq-8, p.enPassanteSq+8
+		}
+		if p.enPassanteSq/8 != row ||
+			!p.bitboards[1-p.activeSide][Pawns].BitIsSet(pawnSq) ||
+			p.AllOccupiedSqsBb().BitIsSet(p.enPassanteSq) ||
+			p.AllOccupiedSqsBb().BitIsSet(originSq) {
+			return fmt.Errorf("invalid fen: en passant square %s", convertIndexToAlgebraic(p.enPassanteSq))
+		}
 	}
-	if activeSide != 0 && activeSide != 1 {
-		return errors.New("invalid active side encoded in FEN string")
+	if p.IsSquareAttacked(p.bitboards[1-p.activeSide][King].Lsb(), p.activeSide) {
+		return errors.New("invalid fen: the side not to move is in check")
 	}
 	return nil
 }
@@ -8,21 +8,23 @@ import (
 )
 
 func TestTokenizeFen(t *testing.T) {
-	position, activeSide, castlingRights, enPassante, moveCt, halfMoveCt := getFenStringTokens("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
+	position, activeSide, castlingRights, enPassante, halfMoveCt, moveCt, err := getFenStringTokens("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
+	assert.Nil(t, err)
 	assert.Equal(t, position, "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR")
 	assert.Equal(t, activeSide, White)
 	assert.Equal(t, castlingRights, "KQkq")
 	assert.Equal(t, enPassante, 64)
-	assert.Equal(t, moveCt, 0)
-	assert.Equal(t, halfMoveCt, 1)
+	assert.Equal(t, halfMoveCt, 0)
+	assert.Equal(t, moveCt, 1)
 
-	position, activeSide, castlingRights, enPassante, moveCt, halfMoveCt = getFenStringTokens("rnbqkbnr/pp1ppppp/8/2p5/4P3/5N2/PPPP1PPP/RNBQKB1R b q e3 1 2")
+	position, activeSide, castlingRights, enPassante, halfMoveCt, moveCt, err = getFenStringTokens("rnbqkbnr/pp1ppppp/8/2p5/4P3/5N2/PPPP1PPP/RNBQKB1R b q e3 1 2")
+	assert.Nil(t, err)
 	assert.Equal(t, position, "rnbqkbnr/pp1ppppp/8/2p5/4P3/5N2/PPPP1PPP/RNBQKB1R")
 	assert.Equal(t, activeSide, Black)
 	assert.Equal(t, castlingRights, "q")
 	assert.Equal(t, enPassante, 44)
-	assert.Equal(t, moveCt, 1)
-	assert.Equal(t, halfMoveCt, 2)
+	assert.Equal(t, halfMoveCt, 1)
+	assert.Equal(t, moveCt, 2)
 }
 
 func TestPositionContructorFen(t *testing.T) {
@@ -35,17 +37,56 @@ func TestPositionContructorFen(t *testing.T) {
 	position, _ = NewPositionFen("rnbqkbnr/pp1ppppp/8/2p5/4P3/5N2/PPPP1PPP/RNBQKB1R b - - 1 2")
 	assert.Equal(t, "rnbqkbnr/pp1ppppp/8/2p5/4P3/5N2/PPPP1PPP/RNBQKB1R b - - 1 2", position.GetFenString())
 
-	position, _ = NewPositionFen("7k/8/8/8/8/8/8/6KB w q - 0 1")
-	assert.Equal(t, "7k/8/8/8/8/8/8/6KB w q - 0 1", position.GetFenString())
+	position, _ = NewPositionFen("r3k3/8/8/8/8/8/8/6KB w q - 0 1")
+	assert.Equal(t, "r3k3/8/8/8/8/8/8/6KB w q - 0 1", position.GetFenString())
 
-	position, _ = NewPositionFen("7k/8/8/8/8/8/8/Rq6 w KQkq - 0 1")
-	assert.Equal(t, "7k/8/8/8/8/8/8/Rq6 w KQkq - 0 1", position.GetFenString())
+	position, _ = NewPositionFen("r3k2r/8/8/8/8/8/8/Rq2K2R w KQkq - 0 1")
+	assert.Equal(t, "r3k2r/8/8/8/8/8/8/Rq2K2R w KQkq - 0 1", position.GetFenString())
+}
+
+func TestPositionContructorFenWithoutCounters(t *testing.T) {
+	position, err := NewPositionFen("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq -")
+	assert.Nil(t, err)
+	assert.Equal(t, "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", position.GetFenString())
+}
+
+func TestPositionContructorFenErrors(t *testing.T) {
+	tests := map[string]struct {
+		fen string
+		err string
+	}{
+		"empty":                    {"", `invalid fen: expected 6 fields, got 0 in ""`},
+		"missing fields":           {"4k3/8/8/8/8/8/8/4K3 w", `invalid fen: expected 6 fields, got 2 in "4k3/8/8/8/8/8/8/4K3 w"`},
+		"seven ranks":              {"4k3/8/8/8/8/8/4K3 w - - 0 1", "invalid fen: 7 ranks in piece placement 4k3/8/8/8/8/8/4K3"},
+		"long rank":                {"4k3/ppppppppp/8/8/8/8/8/4K3 w - - 0 1", "invalid fen: rank 7 has 9 squares"},
+		"short rank":               {"4k3/7/8/8/8/8/8/4K3 w - - 0 1", "invalid fen: rank 7 has 7 squares"},
+		"bad piece letter":         {"4k3/8/8/8/8/8/8/4K2X w - - 0 1", `invalid fen: unknown piece 'X'`},
+		"bad side":                 {"4k3/8/8/8/8/8/8/4K3 x - - 0 1", "invalid fen: side to move must be w or b, got x"},
+		"bad castling":             {"4k3/8/8/8/8/8/8/4K3 w KK - 0 1", "invalid fen: castling rights KK"},
+		"bad en passant":           {"4k3/8/8/8/8/8/8/4K3 w - e9 0 1", "invalid fen: en passant square e9"},
+		"bad half move clock":      {"4k3/8/8/8/8/8/8/4K3 w - - x 1", "invalid fen: half move clock x"},
+		"negative move number":     {"4k3/8/8/8/8/8/8/4K3 w - - 0 -1", "invalid fen: full move number -1"},
+		"move number zero":         {"4k3/8/8/8/8/8/8/4K3 w - - 0 0", "invalid fen: full move number 0"},
+		"missing king":             {"8/8/8/8/8/8/8/4K3 w - - 0 1", "invalid fen: black has 0 kings"},
+		"two kings":                {"4k3/8/8/8/8/8/8/3KK3 w - - 0 1", "invalid fen: white has 2 kings"},
+		"pawn on last rank":        {"4k2P/8/8/8/8/8/8/4K3 w - - 0 1", "invalid fen: pawn on the first or last rank"},
+		"castling without rook":    {"4k3/8/8/8/8/8/8/4K3 w K - 0 1", "invalid fen: castling right K without king and rook on their squares"},
+		"castling with moved king": {"r3k3/8/8/8/8/8/8/R2K4 b Qq - 0 1", "invalid fen: castling right Q without king and rook on their squares"},
+		"en passant without pawn":  {"4k3/8/8/8/8/8/8/4K3 b - e3 0 1", "invalid fen: en passant square e3"},
+		"en passant wrong rank":    {"4k3/8/8/8/4P3/8/8/4K3 w - e3 0 1", "invalid fen: en passant square e3"},
+		"side not to move checked": {"4k3/8/8/8/8/8/8/4RK2 w - - 0 1", "invalid fen: the side not to move is in check"},
+	}
+	for name, test := range tests {
+		position, err := NewPositionFen(test.fen)
+		assert.Nil(t, position, name)
+		assert.EqualError(t, err, test.err, name)
+	}
 }
 
 func TestPositionUpdate(t *testing.T) {
 	position, _ := NewPositionFen("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
 	position.MakeMoveAlgebraic("e2", "e3")
-	assert.Equal(t, position.GetFenString(), "rnbqkbnr/pppppppp/8/8/8/4P3/PPPP1PPP/RNBQKBNR b KQkq - 1 1")
+	assert.Equal(t, position.GetFenString(), "rnbqkbnr/pppppppp/8/8/8/4P3/PPPP1PPP/RNBQKBNR b KQkq - 0 1")
 }
 
 func TestWhiteCanCastleKingSide(t *testing.T) {
@@ -76,11 +117,11 @@ func TestBlackCanCastleQueenSide(t *testing.T) {
 	assert.False(t, position.BlackCanCastleQueenSide())
 }
 
-func TestUnMakeMove(t *testing.T) {
+func TestUnmakeMove(t *testing.T) {
 	// unmake single move
 	position, _ := NewPositionFen("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
 	position.MakeMoveAlgebraic("e2", "e3")
-	position = position.UnMakeMove()
+	position.UnmakeMove()
 	assert.Equal(t, position.GetFenString(), "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
 
 	// unmaking multiple moves in a row
@@ -88,24 +129,24 @@ func TestUnMakeMove(t *testing.T) {
 	position.MakeMoveAlgebraic("e2", "e3")
 	position.MakeMoveAlgebraic("e7", "e6")
 	position.MakeMoveAlgebraic("d2", "d4")
-	assert.Equal(t, "rnbqkbnr/pppp1ppp/4p3/8/3P4/4P3/PPP2PPP/RNBQKBNR b KQkq d3 2 1", position.GetFenString())
-	position = position.UnMakeMove()
-	position = position.UnMakeMove()
-	position = position.UnMakeMove()
+	assert.Equal(t, "rnbqkbnr/pppp1ppp/4p3/8/3P4/4P3/PPP2PPP/RNBQKBNR b KQkq d3 0 2", position.GetFenString())
+	position.UnmakeMove()
+	position.UnmakeMove()
+	position.UnmakeMove()
 	assert.Equal(t, position.GetFenString(), "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
 
 	// unmake attacking move
-	position, _ = NewPositionFen("7k/8/8/8/8/8/7p/6KR w q - 0 1")
+	position, _ = NewPositionFen("r3k3/8/8/8/8/8/7p/6KR w q - 0 1")
 	position.MakeMoveAlgebraic("h1", "h2")
-	assert.Equal(t, position.GetFenString(), "7k/8/8/8/8/8/7R/6K1 b q - 1 1")
-	position = position.UnMakeMove()
-	assert.Equal(t, position.GetFenString(), "7k/8/8/8/8/8/7p/6KR w q - 0 1")
+	assert.Equal(t, position.GetFenString(), "r3k3/8/8/8/8/8/7R/6K1 b q - 0 1")
+	position.UnmakeMove()
+	assert.Equal(t, position.GetFenString(), "r3k3/8/8/8/8/8/7p/6KR w q - 0 1")
 
 	//unmake en passante move
 	position, _ = NewPositionFen("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
 	position.MakeMoveAlgebraic("e2", "e4")
-	assert.Equal(t, position.GetFenString(), "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 1 1")
-	position = position.UnMakeMove()
+	assert.Equal(t, position.GetFenString(), "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1")
+	position.UnmakeMove()
 	assert.Equal(t, position.GetFenString(), "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
 }
 
@@ -128,12 +169,12 @@ func TestCastling(t *testing.T) {
 		"moving black king remove castling": {
 			pos:      "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R b KQkq - 0 1",
 			move:     [2]string{"e8", "g8"},
-			expected: "r4rk1/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQ - 0 1",
+			expected: "r4rk1/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQ - 1 2",
 		},
 		"moving black king removes castling rights 2": {
 			pos:      "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R b KQkq - 0 1",
 			move:     [2]string{"e8", "c8"},
-			expected: "2kr3r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQ - 0 1",
+			expected: "2kr3r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQ - 1 2",
 		},
 		"moving white rook removes queenside castling rights": {
 			pos:      "r3k2r/p1ppqNb1/bn2pnp1/3P4/4P3/2p2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
@@ -148,19 +189,34 @@ func TestCastling(t *testing.T) {
 		"moving black rook removes queenside castling rights": {
 			pos:      "r3k2r/p1ppqNb1/bn2pnp1/3P4/4P3/2p2Q1p/PPPBBPPP/R3K2R b KQkq - 0 1",
 			move:     [2]string{"a8", "b8"},
-			expected: "1r2k2r/p1ppqNb1/bn2pnp1/3P4/4P3/2p2Q1p/PPPBBPPP/R3K2R w KQk - 0 1",
+			expected: "1r2k2r/p1ppqNb1/bn2pnp1/3P4/4P3/2p2Q1p/PPPBBPPP/R3K2R w KQk - 1 2",
 		},
 		"moving black rook removes kingside castling rights": {
 			pos:      "r3k2r/p1ppqNb1/bn2pnp1/3P4/4P3/2p2Q1p/PPPBBPPP/R3K2R b KQkq - 0 1",
 			move:     [2]string{"h8", "g8"},
-			expected: "r3k1r1/p1ppqNb1/bn2pnp1/3P4/4P3/2p2Q1p/PPPBBPPP/R3K2R w KQq - 0 1",
+			expected: "r3k1r1/p1ppqNb1/bn2pnp1/3P4/4P3/2p2Q1p/PPPBBPPP/R3K2R w KQq - 1 2",
+		},
+		"moving white rook removes queenside castling rights without kingside rights": {
+			pos:      "r3k2r/8/8/8/8/8/8/R3K2R w Qkq - 0 1",
+			move:     [2]string{"a1", "b1"},
+			expected: "r3k2r/8/8/8/8/8/8/1R2K2R b kq - 1 1",
+		},
+		"capturing a rook in the corner removes castling rights": {
+			pos:      "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1",
+			move:     [2]string{"a1", "a8"},
+			expected: "R3k2r/8/8/8/8/8/8/4K2R b Kk - 0 1",
+		},
+		"capturing a rook off the corner keeps castling rights": {
+			pos:      "r3k2r/8/8/8/7R/8/8/R3K2R b KQkq - 0 1",
+			move:     [2]string{"h8", "h4"},
+			expected: "r3k3/8/8/8/7r/8/8/R3K2R w KQq - 0 2",
 		},
 	}
 	for tName, test := range tests {
 		position, _ := NewPositionFen(test.pos)
 		position.MakeMoveAlgebraic(test.move[0], test.move[1])
 		assert.Equal(t, test.expected, position.GetFenString(), tName)
-		position = position.UnMakeMove()
+		position.UnmakeMove()
 		assert.Equal(t, test.pos, position.GetFenString(), tName)
 	}
 }
@@ -168,7 +224,73 @@ func TestCastling(t *testing.T) {
 func TestEnPassanteAttackMove(t *testing.T) {
 	position, _ := NewPositionFen("r3k2r/p1ppqNb1/1n2pnp1/1b1P4/Pp2P3/2N2Q1p/1PPBBPPP/R3K2R b KQkq a3 0 1")
 	position.MakeMoveAlgebraic("b4", "a3")
-	assert.Equal(t, "r3k2r/p1ppqNb1/1n2pnp1/1b1P4/4P3/p1N2Q1p/1PPBBPPP/R3K2R w KQkq - 0 1", position.GetFenString())
+	assert.Equal(t, "r3k2r/p1ppqNb1/1n2pnp1/1b1P4/4P3/p1N2Q1p/1PPBBPPP/R3K2R w KQkq - 0 2", position.GetFenString())
+}
+
+func TestUnmakeRestoresPosition(t *testing.T) {
+	tests := map[string]struct {
+		pos  string
+		move moves.Move
+	}{
+		"en passante capture":  {"4k3/8/8/8/Pp6/8/8/4K3 b - a3 0 1", *moves.NewMove([]int{33, 40})},
+		"promotion":            {"4k3/P7/8/8/8/8/8/4K3 w - - 0 1", *moves.NewPromoMove([]int{8, 0, Queen})},
+		"promotion by capture": {"1r2k3/P7/8/8/8/8/8/4K3 w - - 0 1", *moves.NewPromoMove([]int{8, 1, Knights})},
+		"castling":             {"r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 3 9", *moves.NewMove([]int{4, 2})},
+		"capture":              {"4k3/8/3p4/8/4N3/8/8/4K3 w - - 7 20", *moves.NewMove([]int{36, 19})},
+	}
+	for name, test := range tests {
+		position, _ := NewPositionFen(test.pos)
+		position.Move(test.move)
+		assert.NotEqual(t, test.pos, position.GetFenString(), name)
+		assert.True(t, position.UnmakeMove(), name)
+		assert.Equal(t, test.pos, position.GetFenString(), name)
+		assert.Equal(t, position.CalculateHash(), position.Hash(), name)
+		assert.False(t, position.UnmakeMove(), name)
+	}
+}
+
+func TestNullMove(t *testing.T) {
+	fen := "rnbqkbnr/ppp1pppp/8/8/3pP3/8/PPPP1PPP/RNBQKBNR b KQkq e3 4 3"
+	position, _ := NewPositionFen(fen)
+	position.MakeNullMove()
+	assert.Equal(t, "rnbqkbnr/ppp1pppp/8/8/3pP3/8/PPPP1PPP/RNBQKBNR w KQkq - 0 4", position.GetFenString())
+	assert.Equal(t, position.CalculateHash(), position.Hash())
+
+	position.MakeMoveAlgebraic("g1", "f3")
+	position.MakeNullMove()
+	assert.Equal(t, 0, position.RepetitionCount(), "no repetition across a null move")
+	position.UnmakeMove()
+	position.UnmakeMove()
+	assert.True(t, position.UnmakeMove())
+	assert.Equal(t, fen, position.GetFenString())
+	assert.Equal(t, position.CalculateHash(), position.Hash())
+}
+
+func TestMakeMoveDoesNotAllocate(t *testing.T) {
+	position := StartingPosition()
+	allocs := testing.AllocsPerRun(100, func() {
+		makeAndUnmakeOpening(position)
+	})
+	assert.Equal(t, float64(0), allocs)
+}
+
+func BenchmarkMakeUnmakeMove(b *testing.B) {
+	position := StartingPosition()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		makeAndUnmakeOpening(position)
+	}
+}
+
+// makeAndUnmakeOpening plays and takes back a few moves including a capture and castling
+func makeAndUnmakeOpening(position *Position) {
+	line := [][2]int{{52, 36}, {12, 28}, {62, 45}, {1, 18}, {61, 34}, {11, 19}, {60, 62}, {2, 38}, {45, 28}}
+	for _, mv := range line {
+		position.MakeMove(mv[0], mv[1])
+	}
+	for range line {
+		position.UnmakeMove()
+	}
 }
 
 func TestIsCastlingMove(t *testing.T) {
@@ -192,3 +314,144 @@ func TestIsCastlingMove(t *testing.T) {
 	mv = moves.NewMove([]int{60, 61})
 	assert.False(t, position.IsCastlingMove(*mv))
 }
+
+func TestPieceAt(t *testing.T) {
+	position, _ := NewPositionFen("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+	piece, side := position.PieceAt(0)
+	assert.Equal(t, Rooks, piece)
+	assert.Equal(t, Black, side)
+	piece, side = position.PieceAt(28)
+	assert.Equal(t, Knights, piece)
+	assert.Equal(t, White, side)
+	piece, side = position.PieceAt(60)
+	assert.Equal(t, King, piece)
+	assert.Equal(t, White, side)
+	piece, _ = position.PieceAt(1)
+	assert.Equal(t, 0, piece)
+}
+
+func TestHalfMoveClock(t *testing.T) {
+	position, _ := NewPositionFen("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 10 20")
+	position.MakeMoveAlgebraic("e1", "d1")
+	assert.Equal(t, "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R2K3R b kq - 11 20", position.GetFenString())
+	position.MakeMoveAlgebraic("e7", "d8")
+	assert.Equal(t, "r2qk2r/p1pp1pb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R2K3R w kq - 12 21", position.GetFenString())
+	position.MakeMoveAlgebraic("f3", "h3")
+	assert.Equal(t, "r2qk2r/p1pp1pb1/bn2pnp1/3PN3/1p2P3/2N4Q/PPPBBPPP/R2K3R b kq - 0 21", position.GetFenString(), "capture resets the clock")
+	position.MakeMoveAlgebraic("c7", "c6")
+	assert.Equal(t, "r2qk2r/p2p1pb1/bnp1pnp1/3PN3/1p2P3/2N4Q/PPPBBPPP/R2K3R w kq - 0 22", position.GetFenString(), "pawn move resets the clock")
+}
+
+func TestRepetition(t *testing.T) {
+	position, _ := NewPositionFen("7k/8/8/8/8/8/8/1Q5K w - - 0 1")
+	shuffle := [][2]string{{"h1", "g1"}, {"h8", "g8"}, {"g1", "h1"}, {"g8", "h8"}}
+	for _, move := range shuffle {
+		position.MakeMoveAlgebraic(move[0], move[1])
+	}
+	assert.Equal(t, 1, position.RepetitionCount())
+	assert.False(t, position.IsRepetition())
+	for _, move := range shuffle {
+		position.MakeMoveAlgebraic(move[0], move[1])
+	}
+	assert.Equal(t, 2, position.RepetitionCount())
+	assert.True(t, position.IsRepetition())
+
+	// a pawn move makes earlier positions unreachable
+	position, _ = NewPositionFen("7k/8/8/8/8/8/P7/7K w - - 0 1")
+	position.MakeMoveAlgebraic("h1", "g1")
+	position.MakeMoveAlgebraic("h8", "g8")
+	position.MakeMoveAlgebraic("a2", "a3")
+	position.MakeMoveAlgebraic("g8", "h8")
+	position.MakeMoveAlgebraic("g1", "h1")
+	assert.Equal(t, 0, position.RepetitionCount())
+
+	// the en passante square counts only when it can be captured on
+	position = StartingPosition()
+	for _, move := range [][2]string{{"e2", "e4"}, {"g8", "f6"}, {"g1", "f3"}, {"f6", "g8"}, {"f3", "g1"}} {
+		position.MakeMoveAlgebraic(move[0], move[1])
+	}
+	assert.Equal(t, 1, position.RepetitionCount(), "no black pawn can take on e3")
+	assert.Equal(t, position.CalculateHash(), position.Hash())
+	position, _ = NewPositionFen("4k3/8/8/8/5p2/8/4P3/4K2N w - - 0 1")
+	for _, move := range [][2]string{{"e2", "e4"}, {"e8", "d8"}, {"h1", "g3"}, {"d8", "e8"}, {"g3", "h1"}} {
+		position.MakeMoveAlgebraic(move[0], move[1])
+	}
+	assert.Equal(t, 0, position.RepetitionCount(), "the pawn on f4 could take on e3")
+	assert.Equal(t, position.CalculateHash(), position.Hash())
+}
+
+func TestFiftyMoveDraw(t *testing.T) {
+	position, _ := NewPositionFen("7k/8/8/8/8/8/8/1Q5K w - - 99 80")
+	assert.False(t, position.IsFiftyMoveDraw())
+	position.MakeMoveAlgebraic("h1", "g1")
+	assert.True(t, position.IsFiftyMoveDraw())
+}
+
+func TestInsufficientMaterial(t *testing.T) {
+	tests := map[string]struct {
+		pos          string
+		insufficient bool
+	}{
+		"kings only":                  {"7k/8/8/8/8/8/8/7K w - - 0 1", true},
+		"single knight":               {"7k/8/8/8/8/8/8/6NK w - - 0 1", true},
+		"single bishop":               {"7k/8/8/8/8/8/8/5b1K w - - 0 1", true},
+		"bishops on the same color":   {"5b1k/8/8/8/8/8/8/2B4K w - - 0 1", true},
+		"bishops on different colors": {"6bk/8/8/8/8/8/8/2B4K w - - 0 1", false},
+		"two knights":                 {"7k/8/8/8/8/8/8/5NNK w - - 0 1", false},
+		"pawn":                        {"7k/8/8/8/8/8/P7/7K w - - 0 1", false},
+		"rook":                        {"7k/8/8/8/8/8/8/6RK w - - 0 1", false},
+	}
+	for name, test := range tests {
+		position, _ := NewPositionFen(test.pos)
+		assert.Equal(t, test.insufficient, position.IsInsufficientMaterial(), name)
+	}
+}
+
+func TestAttackersTo(t *testing.T) {
+	pos := StartingPosition()
+	// e3 and f3
+	assert.Equal(t, uint64(1)<<51|uint64(1)<<53, pos.AttackersTo(44, White).Value())
+	assert.Equal(t, uint64(1)<<52|uint64(1)<<54|uint64(1)<<62, pos.AttackersTo(45, White).Value())
+	// f6 for black
+	assert.Equal(t, uint64(1)<<12|uint64(1)<<14|uint64(1)<<6, pos.AttackersTo(21, Black).Value())
+	assert.False(t, pos.IsSquareAttacked(36, White))
+
+	// the king blocks the rook from f1
+	pos, _ = NewPositionFen("4k3/8/8/8/8/8/8/R3K3 w - - 0 1")
+	assert.Equal(t, uint64(1)<<56|uint64(1)<<60, pos.AttackersTo(59, White).Value())
+	assert.Equal(t, uint64(1)<<60, pos.AttackersTo(61, White).Value())
+	assert.True(t, pos.IsSquareAttacked(0, White))
+	assert.False(t, pos.IsSquareAttacked(60, Black))
+}
+
+func TestCheckers(t *testing.T) {
+	pos, _ := NewPositionFen("4r1k1/8/8/8/8/5n2/8/4KQ2 w - - 0 1")
+	assert.Equal(t, uint64(1)<<4|uint64(1)<<45, pos.Checkers().Value(), "double check")
+	pos, _ = NewPositionFen("4r1k1/8/8/8/8/8/8/4KQ2 w - - 0 1")
+	assert.Equal(t, uint64(1)<<4, pos.Checkers().Value())
+	assert.Equal(t, uint64(0), StartingPosition().Checkers().Value())
+}
+
+func TestInCheck(t *testing.T) {
+	tests := map[string]struct {
+		pos     string
+		inCheck bool
+	}{
+		"starting position":           {"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", false},
+		"rook on open file":           {"4k3/8/8/8/8/8/8/4R1K1 b - - 0 1", true},
+		"rook blocked":                {"4k3/4p3/8/8/8/8/8/4R1K1 b - - 0 1", false},
+		"bishop on diagonal":          {"4k3/8/8/8/B7/8/8/6K1 b - - 0 1", true},
+		"queen off the diagonal":      {"6k1/8/8/8/8/8/8/Q5K1 b - - 0 1", false},
+		"knight":                      {"4k3/8/3N4/8/8/8/8/6K1 b - - 0 1", true},
+		"white pawn attacks black":    {"4k3/3P4/8/8/8/8/8/6K1 b - - 0 1", true},
+		"black pawn attacks white":    {"4k3/8/8/8/8/8/5p2/6K1 w - - 0 1", true},
+		"pawn does not wrap the edge": {"4k3/8/8/8/8/7p/8/K7 w - - 0 1", false},
+		"pawn in front is no check":   {"4k3/4P3/8/8/8/8/8/6K1 b - - 0 1", false},
+		"fools mate":                  {"rnb1kbnr/pppp1ppp/8/4p3/6Pq/5P2/PPPPP2P/RNBQKBNR w KQkq - 1 3", true},
+	}
+	for name, test := range tests {
+		pos, err := NewPositionFen(test.pos)
+		assert.Nil(t, err, name)
+		assert.Equal(t, test.inCheck, pos.InCheck(), name)
+	}
+}
@@ -0,0 +1,101 @@
+package position
+
+// zobristKeys holds the random numbers used to build a position's Zobrist hash.
+// Keys are generated from a fixed seed so hashes are stable between runs.
+type zobristKeys struct {
+	pieces      [2][7][64]uint64
+	castling    [16]uint64
+	enPassante  [8]uint64
+	blackToMove uint64
+}
+
+var zobrist = newZobristKeys()
+
+func newZobristKeys() *zobristKeys {
+	keys := new(zobristKeys)
+	seed := uint64(0x9E3779B97F4A7C15)
+	next := func() uint64 {
+		// xorshift64*
+		seed ^= seed >> 12
+		seed ^= seed << 25
+		seed ^= seed >> 27
+		return seed * 0x2545F4914F6CDD1D
+	}
+	for side := White; side <= Black; side++ {
+		for piece := King; piece <= Pawns; piece++ {
+			for sq := 0; sq < 64; sq++ {
+				keys.pieces[side][piece][sq] = next()
+			}
+		}
+	}
+	for i := range keys.castling {
+		keys.castling[i] = next()
+	}
+	for i := range keys.enPassante {
+		keys.enPassante[i] = next()
+	}
+	keys.blackToMove = next()
+	return keys
+}
+
+// Hash returns the Zobrist key of the position
+func (p *Position) Hash() uint64 {
+	return p.hash
+}
+
+// CalculateHash computes the Zobrist key of the position from scratch.
+// MakeMove keeps Hash() up to date incrementally, this is used to set the
+// initial key and to verify the incremental updates.
+func (p *Position) CalculateHash() uint64 {
+	var hash uint64
+	for side := White; side <= Black; side++ {
+		for piece := King; piece <= Pawns; piece++ {
+			bb := p.bitboards[side][piece]
+			for !bb.IsZero() {
+				sq := bb.Lsb()
+				bb.RemoveBit(sq)
+				hash ^= zobrist.pieces[side][piece][sq]
+			}
+		}
+	}
+	hash ^= p.castlingHash()
+	hash ^= p.enPassanteHash()
+	if p.activeSide == Black {
+		hash ^= zobrist.blackToMove
+	}
+	return hash
+}
+
+func (p *Position) castlingHash() uint64 {
+	rights := 0
+	if p.WhiteCanCastleKingSide() {
+		rights |= 1
+	}
+	if p.WhiteCanCastleQueenSide() {
+		rights |= 2
+	}
+	if p.BlackCanCastleKingSide() {
+		rights |= 4
+	}
+	if p.BlackCanCastleQueenSide() {
+		rights |= 8
+	}
+	return zobrist.castling[rights]
+}
+
+// enPassanteHash hashes the en passante file only when a pawn of the side to move
+// attacks the square, otherwise the position is the same as without the double push
+func (p *Position) enPassanteHash() uint64 {
+	if p.enPassanteSq == 64 ||
+		ht.PawnAttacksBbHash[p.activeSide^1][p.enPassanteSq]&p.bitboards[p.activeSide][Pawns].Value() == 0 {
+		return 0
+	}
+	return zobrist.enPassante[p.enPassanteSq%8]
+}
+
+func (p *Position) togglePieceHash(side int, piece int, sq int) {
+	if piece == 0 {
+		return
+	}
+	p.hash ^= zobrist.pieces[side][piece][sq]
+}
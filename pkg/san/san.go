@@ -0,0 +1,184 @@
+// Package san converts moves to and from Standard Algebraic Notation, e.g. Nf3, exd5, O-O or e8=Q+.
+// Both directions need the position the move is played in.
+package san
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tonyOreglia/glee/pkg/generate"
+	"github.com/tonyOreglia/glee/pkg/moves"
+	"github.com/tonyOreglia/glee/pkg/position"
+)
+
+var promotionPieces = map[byte]int{'Q': position.Queen, 'R': position.Rooks, 'B': position.Bishops, 'N': position.Knights}
+
+// pieceLetters is indexed by piece, pawns have no letter
+var pieceLetters = [...]string{position.King: "K", position.Queen: "Q", position.Bishops: "B", position.Knights: "N", position.Rooks: "R", position.Pawns: ""}
+
+// Format returns the SAN of mv, which must be legal in pos
+func Format(pos *position.Position, mv moves.Move) string {
+	legalMoves := generate.GenerateLegalMoves(pos).GetMovesList()
+	var san string
+	switch {
+	case mv.IsCastle() && mv.Destination() > mv.Origin():
+		san = "O-O"
+	case mv.IsCastle():
+		san = "O-O-O"
+	default:
+		piece, _ := pos.PieceAt(mv.Origin())
+		san = pieceLetters[piece]
+		if piece == position.Pawns {
+			if mv.IsCapture() {
+				san += file(mv.Origin())
+			}
+		} else {
+			san += disambiguation(pos, mv, piece, legalMoves)
+		}
+		if mv.IsCapture() {
+			san += "x"
+		}
+		san += moves.ConvertIndexToAlgebraic(mv.Destination())
+		if mv.PromotionPiece() != 0 {
+			san += "=" + pieceLetters[mv.PromotionPiece()]
+		}
+	}
+	return san + checkSuffix(pos, mv)
+}
+
+// disambiguation returns the file, rank or square of the origin when another piece
+// of the same type can also move to the destination
+func disambiguation(pos *position.Position, mv moves.Move, piece int, legalMoves []moves.Move) string {
+	ambiguous, sameFile, sameRank := false, false, false
+	for _, other := range legalMoves {
+		if other.Destination() != mv.Destination() || other.Origin() == mv.Origin() {
+			continue
+		}
+		if otherPiece, _ := pos.PieceAt(other.Origin()); otherPiece != piece {
+			continue
+		}
+		ambiguous = true
+		sameFile = sameFile || other.Origin()%8 == mv.Origin()%8
+		sameRank = sameRank || other.Origin()/8 == mv.Origin()/8
+	}
+	switch {
+	case !ambiguous:
+		return ""
+	case !sameFile:
+		return file(mv.Origin())
+	case !sameRank:
+		return rank(mv.Origin())
+	}
+	return moves.ConvertIndexToAlgebraic(mv.Origin())
+}
+
+// checkSuffix returns + when mv gives check and # when it mates
+func checkSuffix(pos *position.Position, mv moves.Move) string {
+	pos.Move(mv)
+	defer pos.UnmakeMove()
+	if !pos.InCheck() {
+		return ""
+	}
+	if generate.GenerateLegalMoves(pos).Length() == 0 {
+		return "#"
+	}
+	return "+"
+}
+
+// Parse finds the legal move of pos written as san. Check, mate and annotation
+// markers are ignored and castling may be written with zeros.
+func Parse(pos *position.Position, san string) (moves.Move, error) {
+	text := strings.TrimRight(san, "+#!?")
+	legalMoves := generate.GenerateLegalMoves(pos).GetMovesList()
+	switch text {
+	case "O-O", "0-0":
+		return findCastle(legalMoves, true, san)
+	case "O-O-O", "0-0-0":
+		return findCastle(legalMoves, false, san)
+	}
+
+	piece := position.Pawns
+	if len(text) > 0 {
+		if p := strings.Index("KQBNR", text[:1]); p >= 0 {
+			piece = []int{position.King, position.Queen, position.Bishops, position.Knights, position.Rooks}[p]
+			text = text[1:]
+		}
+	}
+	promotion := 0
+	if piece == position.Pawns && len(text) > 2 {
+		if p, ok := promotionPieces[text[len(text)-1]]; ok {
+			promotion = p
+			text = strings.TrimSuffix(text[:len(text)-1], "=")
+		}
+	}
+	if len(text) < 2 || !isSquare(text[len(text)-2:]) {
+		return 0, fmt.Errorf("invalid move %s", san)
+	}
+	dest, _ := moves.ConvertAlgebriacToIndex(text[len(text)-2:])
+	// whatever is left between the piece and the destination narrows down the origin
+	origin := strings.TrimSuffix(text[:len(text)-2], "x")
+	if len(origin) > 2 {
+		return 0, fmt.Errorf("invalid move %s", san)
+	}
+
+	var found []moves.Move
+	for _, mv := range legalMoves {
+		if mv.Destination() != dest || mv.PromotionPiece() != promotion || mv.IsCastle() {
+			continue
+		}
+		if movingPiece, _ := pos.PieceAt(mv.Origin()); movingPiece != piece {
+			continue
+		}
+		if !matchesOrigin(mv.Origin(), origin) {
+			continue
+		}
+		found = append(found, mv)
+	}
+	switch len(found) {
+	case 0:
+		return 0, fmt.Errorf("illegal move %s", san)
+	case 1:
+		return found[0], nil
+	}
+	return 0, fmt.Errorf("ambiguous move %s", san)
+}
+
+// matchesOrigin reports whether sq matches the file, rank or square given in the disambiguation
+func matchesOrigin(sq int, disambiguation string) bool {
+	for _, c := range disambiguation {
+		switch {
+		case c >= 'a' && c <= 'h':
+			if file(sq) != string(c) {
+				return false
+			}
+		case c >= '1' && c <= '8':
+			if rank(sq) != string(c) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func findCastle(legalMoves []moves.Move, kingSide bool, san string) (moves.Move, error) {
+	for _, mv := range legalMoves {
+		if mv.IsCastle() && (mv.Destination() > mv.Origin()) == kingSide {
+			return mv, nil
+		}
+	}
+	return 0, fmt.Errorf("illegal move %s", san)
+}
+
+func isSquare(text string) bool {
+	return text[0] >= 'a' && text[0] <= 'h' && text[1] >= '1' && text[1] <= '8'
+}
+
+func file(sq int) string {
+	return string(rune('a' + sq%8))
+}
+
+func rank(sq int) string {
+	return string(rune('8' - sq/8))
+}
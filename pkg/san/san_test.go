@@ -0,0 +1,116 @@
+package san
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tonyOreglia/glee/pkg/generate"
+	"github.com/tonyOreglia/glee/pkg/moves"
+	"github.com/tonyOreglia/glee/pkg/position"
+)
+
+const startingFen = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+const kiwipete = "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1"
+
+func TestFormat(t *testing.T) {
+	tt := []struct {
+		name string
+		fen  string
+		uci  string
+		san  string
+	}{
+		{"knight move", startingFen, "g1f3", "Nf3"},
+		{"pawn push", startingFen, "e2e4", "e4"},
+		{"pawn capture", "rnbqkbnr/ppp1pppp/8/3p4/4P3/8/PPPP1PPP/RNBQKBNR w KQkq d6 0 2", "e4d5", "exd5"},
+		{"en passant", "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 2", "e5d6", "exd6"},
+		{"king side castle", kiwipete, "e1g1", "O-O"},
+		{"queen side castle", kiwipete, "e1c1", "O-O-O"},
+		{"promotion", "8/4P3/8/8/8/8/k7/4K3 w - - 0 1", "e7e8q", "e8=Q"},
+		{"promotion check", "k7/4P3/8/8/8/8/8/4K3 w - - 0 1", "e7e8q", "e8=Q+"},
+		{"under promotion capture", "3r3k/4P3/8/8/8/8/8/4K3 w - - 0 1", "e7d8n", "exd8=N"},
+		{"mate", "r1bqkb1r/pppp1ppp/2n2n2/4p2Q/2B1P3/8/PPPP1PPP/RNB1K1NR w KQkq - 4 4", "h5f7", "Qxf7#"},
+		{"file disambiguation", "4k3/8/8/8/8/8/8/1N2KN2 w - - 0 1", "b1d2", "Nbd2"},
+		{"rank disambiguation", "4k3/8/8/8/R7/8/8/R3K3 w - - 0 1", "a1a3", "R1a3"},
+		{"square disambiguation", "k7/8/8/8/8/2Q1Q3/8/4Q2K w - - 0 1", "e3c1", "Qe3c1"},
+		{"pinned piece needs no disambiguation", "4k3/8/2b5/8/8/5N2/8/1N5K w - - 0 1", "b1d2", "Nd2"},
+	}
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			pos, err := position.NewPositionFen(test.fen)
+			assert.Nil(t, err)
+			mv := findUci(t, pos, test.uci)
+			assert.Equal(t, test.san, Format(pos, mv))
+			assert.Equal(t, test.fen, pos.GetFenString(), "position is left unchanged")
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tt := []struct {
+		name string
+		fen  string
+		san  string
+		uci  string
+	}{
+		{"knight move", startingFen, "Nf3", "g1f3"},
+		{"pawn push", startingFen, "e4", "e2e4"},
+		{"pawn capture", "rnbqkbnr/ppp1pppp/8/3p4/4P3/8/PPPP1PPP/RNBQKBNR w KQkq d6 0 2", "exd5", "e4d5"},
+		{"castle", kiwipete, "O-O", "e1g1"},
+		{"castle with zeros", kiwipete, "0-0-0", "e1c1"},
+		{"promotion", "k7/4P3/8/8/8/8/8/4K3 w - - 0 1", "e8=Q+", "e7e8q"},
+		{"promotion without equals sign", "k7/4P3/8/8/8/8/8/4K3 w - - 0 1", "e8R", "e7e8r"},
+		{"mate and annotation", "r1bqkb1r/pppp1ppp/2n2n2/4p2Q/2B1P3/8/PPPP1PPP/RNB1K1NR w KQkq - 4 4", "Qxf7#!", "h5f7"},
+		{"file disambiguation", "4k3/8/8/8/8/8/8/1N2KN2 w - - 0 1", "Nbd2", "b1d2"},
+		{"rank disambiguation", "4k3/8/8/8/R7/8/8/R3K3 w - - 0 1", "R4a3", "a4a3"},
+		{"square disambiguation", "k7/8/8/8/8/2Q1Q3/8/4Q2K w - - 0 1", "Qe3xc1", "e3c1"},
+		{"needless disambiguation", startingFen, "Ngf3", "g1f3"},
+	}
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			pos, err := position.NewPositionFen(test.fen)
+			assert.Nil(t, err)
+			mv, err := Parse(pos, test.san)
+			assert.Nil(t, err)
+			assert.Equal(t, test.uci, mv.String())
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	pos := position.StartingPosition()
+	_, err := Parse(pos, "e5")
+	assert.EqualError(t, err, "illegal move e5")
+	_, err = Parse(pos, "O-O")
+	assert.EqualError(t, err, "illegal move O-O")
+	_, err = Parse(pos, "Nz3")
+	assert.EqualError(t, err, "invalid move Nz3")
+	_, err = Parse(pos, "")
+	assert.EqualError(t, err, "invalid move ")
+
+	pos, _ = position.NewPositionFen("4k3/8/8/8/8/8/8/1N2KN2 w - - 0 1")
+	_, err = Parse(pos, "Nd2")
+	assert.EqualError(t, err, "ambiguous move Nd2")
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, fen := range []string{startingFen, kiwipete, "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1"} {
+		pos, _ := position.NewPositionFen(fen)
+		for _, mv := range generate.GenerateLegalMoves(pos).GetMovesList() {
+			text := Format(pos, mv)
+			parsed, err := Parse(pos, text)
+			assert.Nil(t, err, text)
+			assert.Equal(t, mv, parsed, text)
+		}
+	}
+}
+
+func findUci(t *testing.T, pos *position.Position, uci string) moves.Move {
+	for _, mv := range generate.GenerateLegalMoves(pos).GetMovesList() {
+		if mv.String() == uci {
+			return mv
+		}
+	}
+	t.Fatalf("%s is not legal", uci)
+	return 0
+}
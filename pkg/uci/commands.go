@@ -0,0 +1,210 @@
+package uci
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tonyOreglia/glee/pkg/engine"
+	"github.com/tonyOreglia/glee/pkg/generate"
+	"github.com/tonyOreglia/glee/pkg/moves"
+	"github.com/tonyOreglia/glee/pkg/pgn"
+	"github.com/tonyOreglia/glee/pkg/position"
+)
+
+// setPosition handles "position [startpos | [fen] <fen>] [moves <move>...]", the game
+// returned records the moves played from the position given
+func setPosition(positionTokens []string) (*position.Position, *pgn.Game, error) {
+	tokens := positionTokens[1:]
+	if len(tokens) == 0 {
+		return nil, nil, fmt.Errorf("missing position")
+	}
+	movesIndex := len(tokens)
+	for i, token := range tokens {
+		if token == "moves" {
+			movesIndex = i
+			break
+		}
+	}
+	var p *position.Position
+	if tokens[0] == "startpos" {
+		p = position.StartingPosition()
+	} else {
+		fenTokens := tokens[:movesIndex]
+		if len(fenTokens) > 0 && fenTokens[0] == "fen" {
+			fenTokens = fenTokens[1:]
+		}
+		var err error
+		p, err = position.NewPositionFen(strings.Join(fenTokens, " "))
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	game := pgn.NewGame()
+	game.SetTag("Date", time.Now().Format("2006.01.02"))
+	game.SetStartingPosition(p)
+	if movesIndex == len(tokens) {
+		return p, game, nil
+	}
+	for _, mv := range tokens[movesIndex+1:] {
+		move, found := findMove(mv, generate.GenerateLegalMoves(p))
+		if !found {
+			return nil, nil, fmt.Errorf("illegal move: %s", mv)
+		}
+		game.AddMove(p, move)
+	}
+	return p, game, nil
+}
+
+// findMove looks up a move given in coordinate notation, e.g. e2e4 or e7e8q
+func findMove(mv string, mvs *moves.Moves) (moves.Move, bool) {
+	lookupPromo := map[string]int{
+		// Queen = 2 Bishops = 3 Knights = 4 Rooks = 5
+		"Q": 2,
+		"B": 3,
+		"N": 4,
+		"R": 5,
+	}
+	promotionPiece := 0
+	if len(mv) != 4 && len(mv) != 5 {
+		return moves.Move(0), false
+	}
+	if len(mv) == 5 {
+		promotionPiece = lookupPromo[strings.ToUpper(string(mv[4]))]
+	}
+	origin, err := moves.ConvertAlgebriacToIndex(mv[0:2])
+	if err != nil {
+		return moves.Move(0), false
+	}
+	dest, err := moves.ConvertAlgebriacToIndex(mv[2:4])
+	if err != nil {
+		return moves.Move(0), false
+	}
+	return mvs.FindMove(origin, dest, promotionPiece)
+}
+
+// setOption handles "setoption name <id> [value <x>]". The Hash option replaces tt,
+// the others change the search settings in options.
+func setOption(tt *engine.TranspositionTable, options *engine.Limits, optionTokens []string) *engine.TranspositionTable {
+	if len(optionTokens) < 3 || optionTokens[1] != "name" {
+		log.Errorf("invalid option: %s", strings.Join(optionTokens, " "))
+		return tt
+	}
+	name := optionTokens[2]
+	value := ""
+	if len(optionTokens) >= 5 && optionTokens[3] == "value" {
+		value = optionTokens[4]
+	}
+	switch name {
+	case "Hash":
+		sizeMb, err := strconv.Atoi(value)
+		if err != nil || sizeMb < 1 {
+			log.Errorf("invalid Hash value: %s", value)
+			return tt
+		}
+		log.Infof("resizing transposition table to %dMB", sizeMb)
+		return engine.NewTranspositionTable(sizeMb)
+	case "NullMove", "LateMoveReductions":
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			log.Errorf("invalid %s value: %s", name, value)
+			return tt
+		}
+		if name == "NullMove" {
+			options.DisableNullMove = !enabled
+		} else {
+			options.DisableLateMoveReductions = !enabled
+		}
+	default:
+		log.Errorf("unknown option: %s", name)
+	}
+	return tt
+}
+
+// parseGoCommand converts "go [searchmoves <move>...] [ponder] [wtime <x>] [btime <x>] [winc <x>] [binc <x>]
+// [movestogo <x>] [depth <x>] [nodes <x>] [mate <x>] [movetime <x>] [infinite]" into search limits.
+// Times are given in milliseconds.
+func parseGoCommand(p *position.Position, goCommandTokens []string) (engine.Limits, error) {
+	limits := engine.Limits{}
+	tokens := goCommandTokens[1:]
+	for i := 0; i < len(tokens); i++ {
+		switch tokens[i] {
+		case "infinite":
+			limits.Infinite = true
+		case "ponder":
+			limits.Ponder = true
+		case "searchmoves":
+			mvs := generate.GenerateLegalMoves(p)
+			for i+1 < len(tokens) {
+				move, ok := findMove(tokens[i+1], mvs)
+				if !ok {
+					break
+				}
+				limits.SearchMoves = append(limits.SearchMoves, move)
+				i++
+			}
+		case "wtime", "btime", "winc", "binc", "movestogo", "depth", "nodes", "mate", "movetime":
+			if i+1 >= len(tokens) {
+				return limits, fmt.Errorf("missing value for %s", tokens[i])
+			}
+			value, err := strconv.Atoi(tokens[i+1])
+			if err != nil {
+				return limits, fmt.Errorf("invalid value for %s: %s", tokens[i], tokens[i+1])
+			}
+			setGoLimit(&limits, tokens[i], value)
+			i++
+		default:
+			return limits, fmt.Errorf("unknown go parameter: %s", tokens[i])
+		}
+	}
+	return limits, nil
+}
+
+// dividePerft answers the "go perft <depth>" extension with the leaf nodes below every move
+// followed by the total, in the format reference engines use so the output can be diffed
+func dividePerft(p *position.Position, goCommandTokens []string, send func(string)) error {
+	if len(goCommandTokens) != 3 {
+		return fmt.Errorf("usage: go perft <depth>")
+	}
+	depth, err := strconv.Atoi(goCommandTokens[2])
+	if err != nil || depth < 1 {
+		return fmt.Errorf("invalid perft depth: %s", goCommandTokens[2])
+	}
+	total := 0
+	for _, count := range engine.Divide(p, depth) {
+		total += count.Nodes
+		send(fmt.Sprintf("%s: %d", count.Move.String(), count.Nodes))
+	}
+	send("")
+	send(fmt.Sprintf("Nodes searched: %d", total))
+	return nil
+}
+
+func setGoLimit(limits *engine.Limits, name string, value int) {
+	milliseconds := time.Duration(value) * time.Millisecond
+	switch name {
+	case "wtime":
+		limits.WhiteTime = milliseconds
+	case "btime":
+		limits.BlackTime = milliseconds
+	case "winc":
+		limits.WhiteIncrement = milliseconds
+	case "binc":
+		limits.BlackIncrement = milliseconds
+	case "movestogo":
+		limits.MovesToGo = value
+	case "depth":
+		limits.Depth = value
+	case "nodes":
+		limits.Nodes = value
+	case "mate":
+		// a mate in n moves is found at a depth of 2n-1 plies
+		if limits.Depth == 0 {
+			limits.Depth = 2*value - 1
+		}
+	case "movetime":
+		limits.MoveTime = milliseconds
+	}
+}
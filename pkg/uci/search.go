@@ -0,0 +1,92 @@
+package uci
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tonyOreglia/glee/pkg/engine"
+	"github.com/tonyOreglia/glee/pkg/position"
+)
+
+// searchJob is a search running in the background while UCI commands are read
+type searchJob struct {
+	cancel    context.CancelFunc
+	ponderHit chan struct{}
+	pondering bool
+	infinite  bool
+	done      chan struct{}
+}
+
+// startSearch searches pos in a new goroutine, sending info while searching and bestmove when done.
+// An infinite or ponder search only reports its move once stopped or, when pondering, on ponderhit.
+func startSearch(pos *position.Position, limits engine.Limits, send func(string)) *searchJob {
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &searchJob{
+		cancel:    cancel,
+		ponderHit: make(chan struct{}),
+		pondering: limits.Ponder,
+		infinite:  limits.Infinite,
+		done:      make(chan struct{}),
+	}
+	limits.PonderHit = job.ponderHit
+	limits.OnInfo = func(info engine.Info) {
+		send("info " + info.String())
+	}
+	pos = pos.Copy()
+	go func() {
+		defer close(job.done)
+		result := engine.Search(ctx, pos, limits)
+		if limits.Ponder {
+			select {
+			case <-ctx.Done():
+			case <-limits.PonderHit:
+			}
+		} else if limits.Infinite {
+			<-ctx.Done()
+		}
+		if result.BestMove == 0 {
+			// mate or stalemate, UCI has a null move for positions without a legal move
+			log.Info("no legal move")
+			send("bestmove 0000")
+			return
+		}
+		log.Infof("found best move %s", result.BestMove.String())
+		bestMove := fmt.Sprintf("bestmove %s", result.BestMove.String())
+		if len(result.PV) > 1 {
+			bestMove += fmt.Sprintf(" ponder %s", result.PV[1].String())
+		}
+		send(bestMove)
+	}()
+	return job
+}
+
+// stop cancels the search and waits until its best move has been sent
+func (j *searchJob) stop() {
+	if j == nil {
+		return
+	}
+	j.cancel()
+	<-j.done
+}
+
+// finish waits for the search to complete. Infinite and pondering searches
+// never complete on their own, so they are stopped instead.
+func (j *searchJob) finish() {
+	if j == nil {
+		return
+	}
+	if j.infinite || j.pondering {
+		j.cancel()
+	}
+	<-j.done
+}
+
+// ponderhit switches a pondering search over to the real clock
+func (j *searchJob) ponderhit() {
+	if j == nil || !j.pondering {
+		return
+	}
+	j.pondering = false
+	close(j.ponderHit)
+}
@@ -0,0 +1,137 @@
+// Package uci implements the Universal Chess Interface protocol
+// independently of the transport used to talk to the GUI.
+package uci
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tonyOreglia/glee/pkg/engine"
+	"github.com/tonyOreglia/glee/pkg/pgn"
+	"github.com/tonyOreglia/glee/pkg/position"
+)
+
+// Session holds the state of a single GUI connection and executes its commands
+type Session struct {
+	send      func(string)
+	writeLock sync.Mutex
+	pos       *position.Position
+	tt        *engine.TranspositionTable
+	job       *searchJob
+	// options holds the search settings changed with setoption
+	options engine.Limits
+	// game records the moves of the last position command, record receives it once the game ends
+	game   *pgn.Game
+	record func(*pgn.Game)
+}
+
+// NewSession creates a session that writes its responses, one line at a time, with send
+func NewSession(send func(string)) *Session {
+	return &Session{
+		send: send,
+		pos:  position.StartingPosition(),
+		tt:   engine.NewTranspositionTable(engine.DefaultHashSizeMb),
+	}
+}
+
+// Handle executes a single UCI command. It returns false once the GUI has sent quit.
+func (s *Session) Handle(command string) bool {
+	commandTokens := strings.Fields(command)
+	if len(commandTokens) == 0 {
+		return true
+	}
+	switch commandTokens[0] {
+	case "uci":
+		log.Info("executing uci response")
+		s.write("GLEE-GoLang chEss Engine")
+		s.write("tony.oreglia@gmail.com")
+		s.write("id name GLEE (GoLang chEss Engine) 0.0.1")
+		s.write("id author Tony Oreglia")
+		s.write(fmt.Sprintf("option name Hash type spin default %d min 1 max 1024", engine.DefaultHashSizeMb))
+		s.write("option name NullMove type check default true")
+		s.write("option name LateMoveReductions type check default true")
+		s.write("uciok")
+	case "debug":
+		s.write("info string debug not yet implemented")
+	case "isready":
+		s.write("readyok")
+	case "setoption":
+		s.job.finish()
+		s.tt = setOption(s.tt, &s.options, commandTokens)
+	case "register":
+		s.write("info string register not yet implemented")
+	case "ucinewgame":
+		s.job.finish()
+		s.endGame()
+		s.pos = position.StartingPosition()
+		s.tt.Clear()
+	case "position":
+		s.job.finish()
+		log.Info("setting engine position")
+		pos, game, err := setPosition(commandTokens)
+		if err != nil {
+			log.Errorf("invalid position command: %s", err)
+			s.write(fmt.Sprintf("info string %s", err))
+			return true
+		}
+		s.pos, s.game = pos, game
+	case "go":
+		s.job.finish()
+		if len(commandTokens) > 1 && commandTokens[1] == "perft" {
+			if err := dividePerft(s.pos, commandTokens, s.write); err != nil {
+				s.write(fmt.Sprintf("info string %s", err))
+			}
+			return true
+		}
+		log.Info("calculating best move")
+		limits, err := parseGoCommand(s.pos, commandTokens)
+		if err != nil {
+			log.Errorf("invalid go command: %s", err)
+			s.write(fmt.Sprintf("info string %s", err))
+			return true
+		}
+		limits.TT = s.tt
+		limits.DisableNullMove = s.options.DisableNullMove
+		limits.DisableLateMoveReductions = s.options.DisableLateMoveReductions
+		s.job = startSearch(s.pos, limits, s.write)
+	case "stop":
+		s.job.stop()
+	case "ponderhit":
+		s.job.ponderhit()
+	case "quit":
+		s.job.stop()
+		return false
+	default:
+		s.write(fmt.Sprintf("info string unknown command: %s", commandTokens[0]))
+	}
+	return true
+}
+
+// Close stops any search in progress and hands the game played to the recorder
+func (s *Session) Close() {
+	s.job.stop()
+	s.endGame()
+}
+
+// RecordGames makes the session pass every game played to record, when the GUI
+// starts a new game or the session is closed
+func (s *Session) RecordGames(record func(*pgn.Game)) {
+	s.record = record
+}
+
+func (s *Session) endGame() {
+	if s.record != nil && s.game != nil && len(s.game.Moves) > 0 {
+		s.game.SetResult(pgn.Result(s.pos))
+		s.record(s.game)
+	}
+	s.game = nil
+}
+
+// write sends a single line to the GUI, the search goroutine writes while commands are handled
+func (s *Session) write(msg string) {
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+	s.send(msg)
+}
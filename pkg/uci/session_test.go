@@ -0,0 +1,229 @@
+package uci
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tonyOreglia/glee/pkg/pgn"
+)
+
+// recorder collects everything a session sends
+type recorder struct {
+	sync.Mutex
+	lines []string
+}
+
+func (r *recorder) send(msg string) {
+	r.Lock()
+	defer r.Unlock()
+	r.lines = append(r.lines, msg)
+}
+
+func (r *recorder) last() string {
+	r.Lock()
+	defer r.Unlock()
+	if len(r.lines) == 0 {
+		return ""
+	}
+	return r.lines[len(r.lines)-1]
+}
+
+func TestSessionUCIHandshake(t *testing.T) {
+	out := new(recorder)
+	session := NewSession(out.send)
+	assert.True(t, session.Handle("uci"))
+	assert.Equal(t, "uciok", out.last())
+	assert.True(t, session.Handle("isready"))
+	assert.Equal(t, "readyok", out.last())
+	assert.False(t, session.Handle("quit"))
+}
+
+func TestSessionSetOption(t *testing.T) {
+	out := new(recorder)
+	session := NewSession(out.send)
+	session.Handle("uci")
+	assert.Contains(t, out.lines, "option name NullMove type check default true")
+	assert.Contains(t, out.lines, "option name LateMoveReductions type check default true")
+
+	session.Handle("setoption name NullMove value false")
+	assert.True(t, session.options.DisableNullMove)
+	assert.False(t, session.options.DisableLateMoveReductions)
+	session.Handle("setoption name LateMoveReductions value false")
+	session.Handle("setoption name NullMove value true")
+	assert.False(t, session.options.DisableNullMove)
+	assert.True(t, session.options.DisableLateMoveReductions)
+	session.Handle("setoption name NullMove value maybe")
+	assert.False(t, session.options.DisableNullMove, "invalid values are ignored")
+
+	tt := session.tt
+	session.Handle("setoption name Hash value 2")
+	assert.NotEqual(t, tt, session.tt)
+	session.Handle("go depth 2")
+	session.job.finish()
+	assert.True(t, strings.HasPrefix(out.last(), "bestmove "), out.last())
+}
+
+func TestSessionPosition(t *testing.T) {
+	tests := map[string]struct {
+		command string
+		fen     string
+	}{
+		"start position": {
+			command: "position startpos",
+			fen:     "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		},
+		"start position with moves": {
+			command: "position startpos moves e2e4 e7e5",
+			fen:     "rnbqkbnr/pppp1ppp/8/4p3/4P3/8/PPPP1PPP/RNBQKBNR w KQkq e6 0 2",
+		},
+		"fen with keyword and castling move": {
+			command: "position fen r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1 moves e1g1",
+			fen:     "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R4RK1 b kq - 1 1",
+		},
+		"fen without keyword": {
+			command: "position 6k1/8/8/8/8/8/8/6KR w - - 0 1",
+			fen:     "6k1/8/8/8/8/8/8/6KR w - - 0 1",
+		},
+		"fen without move counters": {
+			command: "position fen 6k1/8/8/8/8/8/8/6KR b - - moves g8f7",
+			fen:     "8/5k2/8/8/8/8/8/6KR w - - 1 2",
+		},
+		"lower case promotion": {
+			command: "position fen 7k/P7/8/8/8/8/8/7K w - - 0 1 moves a7a8q",
+			fen:     "Q6k/8/8/8/8/8/8/7K b - - 0 1",
+		},
+	}
+	for name, test := range tests {
+		session := NewSession(new(recorder).send)
+		session.Handle(test.command)
+		assert.Equal(t, test.fen, session.pos.GetFenString(), name)
+	}
+}
+
+func TestSessionRejectsInvalidPosition(t *testing.T) {
+	out := new(recorder)
+	session := NewSession(out.send)
+	session.Handle("position startpos moves e2e4")
+	fen := session.pos.GetFenString()
+	invalid := []string{
+		"position",
+		"position startpos moves e2e5",
+		"position fen 8/8/8 w",
+		"position fen 8/8/8/8/8/8/8/8 w - - 0 1",
+		"position fen 4k3/8/8/8/8/8/8/4K3 w - - x 1",
+		"position fen 4k3/8/8/8/8/8/8/4RK2 w - - 0 1",
+	}
+	for _, command := range invalid {
+		session.Handle(command)
+		assert.True(t, strings.HasPrefix(out.last(), "info string"), command)
+		assert.Equal(t, fen, session.pos.GetFenString(), command)
+	}
+}
+
+func TestSessionGo(t *testing.T) {
+	out := new(recorder)
+	session := NewSession(out.send)
+	session.Handle("position startpos moves e2e4")
+	session.Handle("go depth 2")
+	session.job.finish()
+	assert.True(t, strings.HasPrefix(out.last(), "bestmove "), out.last())
+	assert.Contains(t, out.lines[len(out.lines)-2], "info depth 2")
+}
+
+func TestSessionGoWithoutLegalMove(t *testing.T) {
+	for _, fen := range []string{
+		// mated
+		"R5k1/5ppp/8/8/8/8/8/6K1 b - - 0 1",
+		// stalemated
+		"7k/5Q2/6K1/8/8/8/8/8 b - - 0 1",
+	} {
+		out := new(recorder)
+		session := NewSession(out.send)
+		session.Handle("position fen " + fen)
+		session.Handle("go depth 3")
+		session.job.finish()
+		assert.Equal(t, "bestmove 0000", out.last(), fen)
+	}
+}
+
+func TestSessionGoPerft(t *testing.T) {
+	out := new(recorder)
+	session := NewSession(out.send)
+	session.Handle("position fen r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+	session.Handle("go perft 2")
+	assert.Equal(t, 48+2, len(out.lines))
+	assert.Equal(t, "a1b1: 43", out.lines[0])
+	assert.Equal(t, "", out.lines[48])
+	assert.Equal(t, "Nodes searched: 2039", out.last())
+	assert.Nil(t, session.job, "perft does not start a search")
+
+	session.Handle("go perft")
+	assert.Equal(t, "info string usage: go perft <depth>", out.last())
+	session.Handle("go perft zero")
+	assert.Equal(t, "info string invalid perft depth: zero", out.last())
+}
+
+func TestSessionStopInfiniteSearch(t *testing.T) {
+	out := new(recorder)
+	session := NewSession(out.send)
+	session.Handle("go infinite")
+	time.Sleep(100 * time.Millisecond)
+	assert.False(t, strings.HasPrefix(out.last(), "bestmove"), "infinite search waits for stop")
+	session.Handle("stop")
+	assert.True(t, strings.HasPrefix(out.last(), "bestmove "), out.last())
+}
+
+func TestSessionRecordsGames(t *testing.T) {
+	var games []*pgn.Game
+	session := NewSession(new(recorder).send)
+	session.RecordGames(func(game *pgn.Game) {
+		games = append(games, game)
+	})
+	session.Handle("position startpos moves f2f3 e7e5")
+	session.Handle("position startpos moves f2f3 e7e5 g2g4 d8h4")
+	session.Handle("ucinewgame")
+	session.Handle("position fen 7k/8/6K1/8/8/8/8/R7 w - - 0 1 moves a1a2")
+	session.Close()
+
+	assert.Equal(t, 2, len(games))
+	assert.Equal(t, "0-1", games[0].Result)
+	assert.Equal(t, "0-1", games[0].Tag("Result"))
+	assert.Equal(t, 4, len(games[0].Moves))
+	assert.Equal(t, "Qh4#", games[0].Moves[3].San)
+	assert.Equal(t, "*", games[1].Result)
+	assert.Equal(t, "7k/8/6K1/8/8/8/8/R7 w - - 0 1", games[1].Tag("FEN"))
+
+	session.Close()
+	assert.Equal(t, 2, len(games), "a game is recorded once")
+}
+
+func TestParseGoCommand(t *testing.T) {
+	session := NewSession(new(recorder).send)
+	limits, err := parseGoCommand(session.pos, strings.Fields("go wtime 60000 btime 50000 winc 1000 binc 2000 movestogo 20"))
+	assert.Nil(t, err)
+	assert.Equal(t, 60*time.Second, limits.WhiteTime)
+	assert.Equal(t, 50*time.Second, limits.BlackTime)
+	assert.Equal(t, time.Second, limits.WhiteIncrement)
+	assert.Equal(t, 2*time.Second, limits.BlackIncrement)
+	assert.Equal(t, 20, limits.MovesToGo)
+
+	limits, err = parseGoCommand(session.pos, strings.Fields("go searchmoves e2e4 d2d4 depth 4 nodes 1000 movetime 300"))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(limits.SearchMoves))
+	assert.Equal(t, 4, limits.Depth)
+	assert.Equal(t, 1000, limits.Nodes)
+	assert.Equal(t, 300*time.Millisecond, limits.MoveTime)
+
+	limits, err = parseGoCommand(session.pos, strings.Fields("go ponder infinite"))
+	assert.Nil(t, err)
+	assert.True(t, limits.Ponder)
+	assert.True(t, limits.Infinite)
+
+	_, err = parseGoCommand(session.pos, strings.Fields("go depth"))
+	assert.NotNil(t, err)
+	_, err = parseGoCommand(session.pos, strings.Fields("go movetime soon"))
+	assert.NotNil(t, err)
+}
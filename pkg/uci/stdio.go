@@ -0,0 +1,23 @@
+package uci
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// Run reads UCI commands line by line from r and writes the responses to w
+// until the GUI sends quit or the input ends, e.g. stdin and stdout of a GUI pipe
+func Run(r io.Reader, w io.Writer) error {
+	session := NewSession(func(msg string) {
+		fmt.Fprintln(w, msg)
+	})
+	defer session.Close()
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if !session.Handle(scanner.Text()) {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
@@ -2,22 +2,29 @@ package websocket
 
 import (
 	"net/http"
+	"os"
+	"sync"
 
-	"github.com/namsral/flag"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/gorilla/websocket"
+	"github.com/tonyOreglia/glee/pkg/pgn"
 )
 
 type WebsocketServer struct {
 	upgrader websocket.Upgrader
-	addr     *string
+	addr     string
+	// pgnPath is the file every game played is appended to, games are not saved when empty
+	pgnPath string
+	pgnLock sync.Mutex
 }
 
-func NewWebsocketServer() *WebsocketServer {
+// NewWebsocketServer creates a server accepting UCI websocket connections at /uci on addr.
+// Games played are appended to the PGN file at pgnPath unless it is empty.
+func NewWebsocketServer(addr string, pgnPath string) *WebsocketServer {
 	w := new(WebsocketServer)
-	w.addr = flag.String("addr", "localhost:8081", "http websocket service address")
-	flag.Parse()
+	w.addr = addr
+	w.pgnPath = pgnPath
 	w.upgrader = websocket.Upgrader{} // use default options
 	http.HandleFunc("/uci", w.uciHandler)
 	return w
@@ -36,7 +43,7 @@ func (w *WebsocketServer) uciHandler(rw http.ResponseWriter, r *http.Request) {
 
 func (w *WebsocketServer) Start() {
 	log.Info("starting websocket server")
-	log.Fatal(http.ListenAndServe(*w.addr, nil))
+	log.Fatal(http.ListenAndServe(w.addr, nil))
 }
 
 func Write(conn *websocket.Conn, msg string) {
@@ -45,3 +52,18 @@ func Write(conn *websocket.Conn, msg string) {
 		log.Println("write:", err)
 	}
 }
+
+// savePgn appends game to the PGN file, connections finish games concurrently
+func (w *WebsocketServer) savePgn(game *pgn.Game) {
+	w.pgnLock.Lock()
+	defer w.pgnLock.Unlock()
+	f, err := os.OpenFile(w.pgnPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Error("saving game: ", err)
+		return
+	}
+	defer f.Close()
+	if err := game.Write(f); err != nil {
+		log.Error("saving game: ", err)
+	}
+}